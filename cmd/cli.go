@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/sysio"
+)
+
+const (
+	CMD_VERIFY  = "verify"
+	CMD_REPAIR  = "repair"
+	CMD_LIBRARY = "library"
+	CMD_LAUNCH  = "launch"
+)
+
+const (
+	LIBRARY_LIST   = "list"
+	LIBRARY_ADD    = "add"
+	LIBRARY_REMOVE = "remove"
+)
+
+const (
+	LAUNCH_ARGS  = "args"
+	LAUNCH_ENV   = "env"
+	LAUNCH_DIR   = "dir"
+	LAUNCH_RESET = "reset"
+)
+
+const (
+	EXIT_OK = iota
+	EXIT_FAILED
+	EXIT_USAGE
+)
+
+// runCommand runs the launcher as a command-line tool instead of opening the
+// window and returns the process exit code.
+func runCommand(args []string, cfg *config.Config, games []requests.Game, providers provider.Registry, sio sysio.Adapter) int {
+	if len(args) > 0 && args[0] == CMD_LIBRARY {
+		return runLibraryCommand(args[1:], cfg, sio)
+	}
+	if len(args) > 1 && args[0] == CMD_LAUNCH {
+		g, ok := findGame(games, args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown game: %s\n", args[1])
+			return EXIT_USAGE
+		}
+		return runLaunchCommand(args[2:], g, cfg, sio)
+	}
+
+	if len(args) != 2 {
+		return usage()
+	}
+
+	g, ok := findGame(games, args[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown game: %s\n", args[1])
+		return EXIT_USAGE
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report := func(p sysio.Progress) {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", p)
+	}
+
+	var result *sysio.VerifyReport
+	var err error
+
+	switch args[0] {
+	case CMD_VERIFY:
+		result, err = sio.VerifyGame(ctx, g, report)
+	case CMD_REPAIR:
+		var p provider.ReleaseProvider
+		p, err = providers.For(g)
+		if err == nil {
+			result, err = sio.RepairGame(ctx, p, g, report)
+		}
+	default:
+		return usage()
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if result != nil {
+		for _, f := range result.Damaged {
+			fmt.Printf("%s\t%s\n", f.Problem, f.Path)
+		}
+		fmt.Println(result)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", args[0], err)
+		return EXIT_FAILED
+	}
+	if args[0] == CMD_VERIFY && !result.OK() {
+		return EXIT_FAILED
+	}
+
+	return EXIT_OK
+}
+
+// runLibraryCommand lists, adds and removes library folders.
+func runLibraryCommand(args []string, cfg *config.Config, sio sysio.Adapter) int {
+	var err error
+
+	switch {
+	case len(args) == 1 && args[0] == LIBRARY_LIST:
+		var libraries []sysio.Library
+		libraries, err = sio.Libraries()
+		for _, l := range libraries {
+			free := "unknown"
+			if l.Free >= 0 {
+				free = sysio.FormatBytes(l.Free)
+			}
+			def := ""
+			if l.Default {
+				def = "\t(default)"
+			}
+			fmt.Printf("%s\t%s free%s\n", l.Path, free, def)
+		}
+	case len(args) == 2 && args[0] == LIBRARY_ADD:
+		var path string
+		if path, err = filepath.Abs(args[1]); err == nil {
+			err = cfg.AddLibrary(path)
+		}
+	case len(args) == 2 && args[0] == LIBRARY_REMOVE:
+		var path string
+		if path, err = filepath.Abs(args[1]); err == nil {
+			err = cfg.RemoveLibrary(path)
+		}
+	default:
+		return usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", CMD_LIBRARY, err)
+		return EXIT_FAILED
+	}
+
+	return EXIT_OK
+}
+
+// runLaunchCommand shows or changes the options a game is launched with.
+// Without a subcommand it prints the options after merging in the
+// registry's defaults.
+func runLaunchCommand(args []string, g requests.Game, cfg *config.Config, sio sysio.Adapter) int {
+	gc := cfg.Game(g.ID)
+
+	switch {
+	case len(args) == 0:
+		opts := sio.LaunchOptions(g)
+
+		fmt.Printf("args\t%s\n", strings.Join(opts.Args, " "))
+		keys := make([]string, 0, len(opts.Env))
+		for k := range opts.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("env\t%s=%s\n", k, opts.Env[k])
+		}
+		fmt.Printf("dir\t%s\n", opts.Dir)
+		return EXIT_OK
+	case args[0] == LAUNCH_ARGS:
+		gc.LaunchArgs = args[1:]
+	case args[0] == LAUNCH_ENV && len(args) > 1:
+		if gc.LaunchEnv == nil {
+			gc.LaunchEnv = make(map[string]string)
+		}
+		// KEY=VALUE sets a variable and a bare KEY drops the override.
+		for _, kv := range args[1:] {
+			k, v, set := strings.Cut(kv, "=")
+			if k == "" {
+				fmt.Fprintf(os.Stderr, "invalid variable: %s\n", kv)
+				return EXIT_USAGE
+			}
+			if set {
+				gc.LaunchEnv[k] = v
+			} else {
+				delete(gc.LaunchEnv, k)
+			}
+		}
+	case args[0] == LAUNCH_DIR && len(args) <= 2:
+		gc.LaunchDir = ""
+		if len(args) == 2 {
+			gc.LaunchDir = args[1]
+		}
+	case args[0] == LAUNCH_RESET && len(args) == 1:
+		gc.LaunchArgs = nil
+		gc.LaunchEnv = nil
+		gc.LaunchDir = ""
+	default:
+		return usage()
+	}
+
+	if err := cfg.SetGame(g.ID, gc); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", CMD_LAUNCH, err)
+		return EXIT_FAILED
+	}
+
+	return EXIT_OK
+}
+
+func usage() int {
+	fmt.Fprintf(os.Stderr, "usage: %s %s|%s <game name or id>\n", os.Args[0], CMD_VERIFY, CMD_REPAIR)
+	fmt.Fprintf(os.Stderr, "       %s %s %s|%s <path>|%s\n", os.Args[0], CMD_LIBRARY, LIBRARY_ADD, LIBRARY_REMOVE, LIBRARY_LIST)
+	fmt.Fprintf(os.Stderr, "       %s %s <game> [%s <arg>...|%s KEY[=VALUE]...|%s [path]|%s]\n", os.Args[0], CMD_LAUNCH, LAUNCH_ARGS, LAUNCH_ENV, LAUNCH_DIR, LAUNCH_RESET)
+	return EXIT_USAGE
+}
+
+func findGame(games []requests.Game, nameOrID string) (requests.Game, bool) {
+	id, idErr := strconv.Atoi(nameOrID)
+
+	for _, g := range games {
+		if (idErr == nil && g.ID == id) || strings.EqualFold(g.Name, nameOrID) {
+			return g, true
+		}
+	}
+
+	return requests.Game{}, false
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/downloads"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/list"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	ACTION_PAUSE  = "Pause"
+	ACTION_RESUME = "Resume"
+	ACTION_RETRY  = "Retry"
+	ACTION_CANCEL = "Cancel"
+	ACTION_UP     = "Up"
+	ACTION_REMOVE = "Remove"
+)
+
+// downloadsPage lists the download manager's jobs with controls for each.
+type downloadsPage struct {
+	*view.View
+	manager *downloads.Manager
+	list    *list.List
+	jobs    []downloads.Job
+	notice  *label.Label
+}
+
+func newDownloadsPage(manager *downloads.Manager, t *etxt.Renderer, clearCache func() error) *downloadsPage {
+	p := &downloadsPage{
+		manager: manager,
+		notice: label.NewLabel(
+			.625, .16,
+			16,
+			color.White,
+			"",
+			t,
+		),
+		list: list.NewList(
+			.27, .2,
+			.71, .75,
+			.07,
+			18,
+			color.RGBA{32, 32, 32, 255},
+			color.RGBA{32, 96, 246, 255},
+			color.White,
+			t,
+		),
+	}
+
+	p.list.AddHandler(list.HANDLER_ON_ACTION, func(l *list.List, row, action int) error {
+		if row >= len(p.jobs) {
+			return nil
+		}
+		j := p.jobs[row]
+
+		var err error
+		a := jobActions(j)[action]
+		switch a {
+		case ACTION_PAUSE:
+			err = manager.Pause(j.ID)
+		case ACTION_RESUME, ACTION_RETRY:
+			err = manager.Resume(j.ID)
+		case ACTION_CANCEL:
+			err = manager.Cancel(j.ID)
+		case ACTION_UP:
+			err = manager.SetPriority(j.ID, j.Priority+1)
+		case ACTION_REMOVE:
+			err = manager.Remove(j.ID)
+		}
+		// The job can change state between the rows being drawn and the
+		// click, so a failed action is only reported.
+		if err != nil {
+			p.SetNotice(fmt.Sprintf("%s %s failed: %v", a, j.GameName, err))
+		}
+
+		p.Refresh()
+		return nil
+	})
+
+	clearButton := button.NewButton(
+		.8, .08,
+		.17, .06,
+		18,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Clear download cache",
+		t,
+	)
+	clearButton.SetState(button.STATE_CUSTOM)
+	clearButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return clearCache()
+	})
+
+	p.View = view.NewView(
+		label.NewLabel(
+			.4, .1,
+			36,
+			color.White,
+			"Downloads",
+			t,
+		),
+		clearButton,
+		p.notice,
+		p.list,
+	)
+
+	p.Refresh()
+
+	return p
+}
+
+// SetNotice shows text above the job list.
+func (p *downloadsPage) SetNotice(text string) {
+	p.notice.SetText(text)
+}
+
+// Refresh rebuilds the rows from the manager. It must be called from the
+// update goroutine.
+func (p *downloadsPage) Refresh() {
+	p.jobs = p.manager.Jobs()
+
+	rows := make([]list.Row, 0, len(p.jobs))
+	for _, j := range p.jobs {
+		rows = append(rows, list.Row{
+			Text:     jobText(j),
+			Fraction: j.Progress.Fraction,
+			Actions:  jobActions(j),
+		})
+	}
+
+	p.list.SetRows(rows)
+}
+
+func jobText(j downloads.Job) string {
+	kind := string(j.Kind)
+	if j.Tag != "" {
+		kind += " " + j.Tag
+	}
+	if j.Library != "" {
+		kind += " to " + j.Library
+	}
+
+	s := fmt.Sprintf("%s · %s · %s", j.GameName, kind, j.State)
+
+	switch {
+	case j.State == downloads.JOB_ACTIVE && j.Progress.Text != "":
+		s += " · " + j.Progress.Text
+	case j.State == downloads.JOB_FAILED:
+		s += " · " + j.Err
+	}
+
+	return s
+}
+
+// activeText is what the library's install button shows while a job for the
+// game is running.
+func activeText(j downloads.Job) string {
+	switch j.Kind {
+	case downloads.JOB_REPAIR:
+		return "Repairing"
+	case downloads.JOB_MOVE:
+		return "Moving"
+	}
+
+	return "Installing"
+}
+
+func jobActions(j downloads.Job) []string {
+	switch j.State {
+	case downloads.JOB_ACTIVE:
+		return []string{ACTION_PAUSE, ACTION_CANCEL}
+	case downloads.JOB_QUEUED:
+		return []string{ACTION_UP, ACTION_PAUSE, ACTION_CANCEL}
+	case downloads.JOB_PAUSED:
+		return []string{ACTION_RESUME, ACTION_CANCEL}
+	case downloads.JOB_FAILED:
+		return []string{ACTION_RETRY, ACTION_REMOVE}
+	default:
+		return []string{ACTION_REMOVE}
+	}
+}
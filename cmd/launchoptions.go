@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/panel"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/textinput"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+var (
+	ErrUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// launchOptionsDialog edits the arguments, environment and working directory
+// a game is launched with, on top of the registry's defaults.
+type launchOptionsDialog struct {
+	*view.View
+	title    *label.Label
+	defaults *label.Label
+	notice   *label.Label
+	args     *textinput.TextInput
+	env      *textinput.TextInput
+	dir      *textinput.TextInput
+	game     requests.Game
+}
+
+func newLaunchOptionsDialog(
+	t *etxt.Renderer,
+	save func(g requests.Game, args []string, env map[string]string, dir string) error,
+	cancel func() error,
+) *launchOptionsDialog {
+	newInput := func(y float32) *textinput.TextInput {
+		return textinput.NewTextInput(
+			.45, y,
+			.42, .06,
+			18,
+			color.RGBA{32, 32, 32, 255},
+			color.White,
+			t,
+		)
+	}
+	newRowLabel := func(y float32, text string) *label.Label {
+		return label.NewLabel(.38, y, 18, color.White, text, t)
+	}
+
+	d := &launchOptionsDialog{
+		title: label.NewLabel(
+			.6, .26,
+			28,
+			color.White,
+			"",
+			t,
+		),
+		defaults: label.NewLabel(
+			.6, .63,
+			16,
+			color.White,
+			"",
+			t,
+		),
+		notice: label.NewLabel(
+			.6, .67,
+			16,
+			color.White,
+			"",
+			t,
+		),
+		args: newInput(.33),
+		env:  newInput(.43),
+		dir:  newInput(.53),
+	}
+
+	saveButton := button.NewButton(
+		.5, .72,
+		.1, .06,
+		20,
+		color.RGBA{32, 96, 246, 255},
+		color.White,
+		"Save",
+		t,
+	)
+	saveButton.SetState(button.STATE_CUSTOM)
+	saveButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		args, err := splitArgs(d.args.GetText())
+		if err != nil {
+			d.notice.SetText(fmt.Sprintf("Arguments: %v", err))
+			return nil
+		}
+
+		env, err := parseEnv(d.env.GetText())
+		if err != nil {
+			d.notice.SetText(fmt.Sprintf("Environment: %v", err))
+			return nil
+		}
+
+		return save(d.game, args, env, strings.TrimSpace(d.dir.GetText()))
+	})
+
+	resetButton := button.NewButton(
+		.62, .72,
+		.1, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Clear",
+		t,
+	)
+	resetButton.SetState(button.STATE_CUSTOM)
+	resetButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		d.args.SetText("")
+		d.env.SetText("")
+		d.dir.SetText("")
+		return nil
+	})
+
+	cancelButton := button.NewButton(
+		.74, .72,
+		.1, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Cancel",
+		t,
+	)
+	cancelButton.SetState(button.STATE_CUSTOM)
+	cancelButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return cancel()
+	})
+
+	d.View = view.NewView(
+		panel.NewPanel(.3, .2, .6, .6, color.RGBA{48, 48, 48, 255}),
+		d.title,
+		newRowLabel(.36, "Arguments"),
+		d.args,
+		newRowLabel(.46, "Environment"),
+		d.env,
+		newRowLabel(.56, "Working directory"),
+		d.dir,
+		d.defaults,
+		d.notice,
+		saveButton,
+		resetButton,
+		cancelButton,
+	)
+	d.SetHidden(true)
+
+	return d
+}
+
+// Show edits the options the user set for g. The registry's defaults are
+// shown for reference since they apply either way.
+func (d *launchOptionsDialog) Show(g requests.Game, gc config.GameConfig) {
+	d.game = g
+	d.title.SetText(fmt.Sprintf("Launch options for %s", g.Name))
+	d.args.SetText(joinArgs(gc.LaunchArgs))
+	d.env.SetText(joinArgs(envPairs(gc.LaunchEnv)))
+	d.dir.SetText(gc.LaunchDir)
+	d.notice.SetText("")
+
+	var defaults []string
+	if len(g.LaunchArgs) > 0 {
+		defaults = append(defaults, "arguments "+joinArgs(g.LaunchArgs))
+	}
+	if len(g.LaunchEnv) > 0 {
+		defaults = append(defaults, "environment "+joinArgs(envPairs(g.LaunchEnv)))
+	}
+	if g.LaunchDir != "" {
+		defaults = append(defaults, "directory "+g.LaunchDir)
+	}
+	if len(defaults) > 0 {
+		d.defaults.SetText("Publisher defaults: " + strings.Join(defaults, " · "))
+	} else {
+		d.defaults.SetText("Arguments follow the publisher's; variables and the directory replace theirs.")
+	}
+
+	d.SetHidden(false)
+}
+
+// splitArgs splits s at spaces. Quotes group words, and inside double quotes
+// a backslash escapes a quote or another backslash. Backslashes are kept
+// elsewhere so Windows paths can be typed as they are.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				cur.WriteRune(runes[i])
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+
+	return args, nil
+}
+
+// joinArgs is the inverse of splitArgs.
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.ContainsAny(a, " \t\"'") {
+			quoted[i] = a
+			continue
+		}
+
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// parseEnv reads KEY=VALUE words as split by splitArgs.
+func parseEnv(s string) (map[string]string, error) {
+	words, err := splitArgs(s)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(words))
+	for _, kv := range words {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid variable: %s", kv)
+		}
+		env[k] = v
+	}
+
+	return env, nil
+}
+
+// envPairs lists env as KEY=VALUE sorted by key.
+func envPairs(env map[string]string) []string {
+	pairs := make([]string, 0, len(env))
+	for k, v := range env {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return pairs
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/DillonEnge/keizai-launcher/internal/gamelog"
+	"github.com/DillonEnge/keizai-launcher/internal/sysio"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/textview"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	// LOG_TAIL_BYTES is how much of the end of a log the viewer reads.
+	LOG_TAIL_BYTES = 256 << 10
+	// LOG_REFRESH_TICKS is how many updates pass between reloads of a log
+	// that is being tailed.
+	LOG_REFRESH_TICKS = 30
+)
+
+// logsPage shows the output the selected game wrote in each of its sessions.
+type logsPage struct {
+	*view.View
+	title      *label.Label
+	session    *label.Label
+	text       *textview.TextView
+	tailButton *button.Button
+	load       func() error
+	sessions   []gamelog.Session
+	selected   string
+	ticks      int
+}
+
+func newLogsPage(t *etxt.Renderer, load func() error, openFolder func() error) *logsPage {
+	p := &logsPage{
+		title: label.NewLabel(
+			.4, .1,
+			36,
+			color.White,
+			"Logs",
+			t,
+		),
+		session: label.NewLabel(
+			.625, .16,
+			16,
+			color.White,
+			"",
+			t,
+		),
+		text: textview.NewTextView(
+			.27, .2,
+			.71, .67,
+			.025,
+			14,
+			color.RGBA{24, 24, 24, 255},
+			color.White,
+			t,
+		),
+		tailButton: button.NewButton(
+			.49, .9,
+			.12, .06,
+			20,
+			color.RGBA{64, 64, 64, 255},
+			color.White,
+			"",
+			t,
+		),
+		load: load,
+	}
+
+	olderButton := button.NewButton(
+		.27, .9,
+		.1, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Older",
+		t,
+	)
+	olderButton.SetState(button.STATE_CUSTOM)
+	olderButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return p.step(1)
+	})
+
+	newerButton := button.NewButton(
+		.38, .9,
+		.1, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Newer",
+		t,
+	)
+	newerButton.SetState(button.STATE_CUSTOM)
+	newerButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return p.step(-1)
+	})
+
+	p.tailButton.SetState(button.STATE_CUSTOM)
+	p.tailButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		p.text.SetTail(!p.text.Tail())
+		return nil
+	})
+
+	openButton := button.NewButton(
+		.82, .9,
+		.16, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Open logs folder",
+		t,
+	)
+	openButton.SetState(button.STATE_CUSTOM)
+	openButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return openFolder()
+	})
+
+	p.View = view.NewView(
+		p.title,
+		p.session,
+		p.text,
+		olderButton,
+		newerButton,
+		p.tailButton,
+		openButton,
+	)
+
+	return p
+}
+
+// Update reloads the log while it is tailed, since the game may still be
+// writing to it.
+func (p *logsPage) Update(g *game.Game) error {
+	if p.IsHidden() {
+		return nil
+	}
+
+	if p.text.Tail() {
+		p.tailButton.SetText("Tail: on")
+	} else {
+		p.tailButton.SetText("Tail: off")
+	}
+
+	p.ticks++
+	if p.ticks%LOG_REFRESH_TICKS == 0 && p.text.Tail() {
+		if err := p.load(); err != nil {
+			return err
+		}
+	}
+
+	return p.View.Update(g)
+}
+
+// Selected is the path of the session to show, or empty for the newest.
+func (p *logsPage) Selected() string {
+	return p.selected
+}
+
+// Reset goes back to the newest session, in tail mode.
+func (p *logsPage) Reset(gameName string) {
+	p.selected = ""
+	p.sessions = nil
+	p.title.SetText(fmt.Sprintf("%s Logs", gameName))
+	p.session.SetText("")
+	p.text.SetLines(nil)
+	p.text.SetTail(true)
+}
+
+// SetLog shows lines from the session at path. It must be called from the
+// update goroutine.
+func (p *logsPage) SetLog(sessions []gamelog.Session, path string, lines []string) {
+	p.sessions = sessions
+
+	i := p.index(path)
+	if i < 0 {
+		p.selected = ""
+		p.session.SetText("No sessions logged yet")
+		p.text.SetLines(nil)
+		return
+	}
+
+	if i == 0 {
+		p.selected = ""
+	}
+
+	s := sessions[i]
+	p.session.SetText(fmt.Sprintf(
+		"Session %d of %d · started %s · %s",
+		len(sessions)-i, len(sessions),
+		s.StartedAt.Format("2006-01-02 15:04:05"),
+		sysio.FormatBytes(s.Size),
+	))
+	p.text.SetLines(lines)
+}
+
+// SetError shows why the logs could not be read.
+func (p *logsPage) SetError(err error) {
+	p.session.SetText(fmt.Sprintf("Failed to read logs: %v", err))
+	p.text.SetLines(nil)
+}
+
+// step moves delta sessions back in time.
+func (p *logsPage) step(delta int) error {
+	if len(p.sessions) == 0 {
+		return nil
+	}
+
+	i := p.index(p.selected)
+	if i < 0 {
+		i = 0
+	}
+
+	i = max(0, min(i+delta, len(p.sessions)-1))
+	p.selected = p.sessions[i].Path
+	if i == 0 {
+		p.selected = ""
+	}
+	p.text.SetTail(true)
+
+	return p.load()
+}
+
+// index finds the session at path, with empty meaning the newest.
+func (p *logsPage) index(path string) int {
+	if len(p.sessions) == 0 {
+		return -1
+	}
+	if path == "" {
+		return 0
+	}
+
+	for i, s := range p.sessions {
+		if s.Path == path {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -2,76 +2,1028 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"image/png"
 	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/downloads"
 	"github.com/DillonEnge/keizai-launcher/internal/fonts"
 	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/DillonEnge/keizai-launcher/internal/gamelog"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
 	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/signing"
+	"github.com/DillonEnge/keizai-launcher/internal/supervisor"
 	"github.com/DillonEnge/keizai-launcher/internal/sysio"
 	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
 	"github.com/DillonEnge/keizai-launcher/internal/ui/drawer"
 	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
 	"github.com/DillonEnge/keizai-launcher/internal/ui/panel"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/progressbar"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
 	"github.com/google/go-github/v62/github"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/tinne26/etxt"
 )
 
+// registryPublicKey is the base64 Ed25519 key the game registry signs its
+// responses with. It is set at build time with
+// -ldflags "-X main.registryPublicKey=<key>".
+var registryPublicKey string
+
 func main() {
 	ebiten.SetWindowSize(1280, 720)
 	ebiten.SetWindowTitle("Engehost Launcher")
 
-	client := requests.Client{URL: "https://game-registry.engehost.net"}
+	cfgPath, err := config.DefaultPath()
+	if err != nil {
+		panic(err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		panic(err)
+	}
+
+	registryKeys, err := signing.ParseKeyring(append(cfg.GetRegistryKeys(), registryPublicKey)...)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(registryKeys) == 0 {
+		slog.Warn("no registry key configured, ignoring publisher keys from the registry")
+	}
+
+	client := requests.Client{URL: "https://game-registry.engehost.net", PublicKeys: registryKeys}
+
+	games, err := client.GetGames()
+	if err != nil {
+		panic(err)
+	}
+
+	providers := provider.Registry{
+		provider.PROVIDER_GITHUB:   provider.NewGitHubProvider(github.NewClient(nil)),
+		provider.PROVIDER_MANIFEST: provider.NewManifestProvider(nil),
+	}
+
+	t, err := newTxtRenderer()
+	if err != nil {
+		slog.Error("failed to create txt renderer", "err", err)
+		return
+	}
+
+	stateDir, err := config.Dir()
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := installdb.Open(filepath.Join(stateDir, installdb.DB_FILE_NAME))
+	if err != nil {
+		panic(err)
+	}
+
+	sio, err := sysio.NewSysio(cfg, db)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], cfg, games, providers, sio))
+	}
+
+	ss := game.NewStateStore()
+	tasks := game.NewTaskRunner()
+
+	gamesByID := make(map[int]requests.Game, len(games))
+	for _, v := range games {
+		gamesByID[v.ID] = v
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		panic(err)
+	}
+
+	// Games keep running when the launcher exits, so the supervisor is never
+	// closed and picks up the ones an earlier launcher left running.
+	running := supervisor.New(supervisor.DEFAULT_STOP_TIMEOUT, dataDir)
+	if err := running.Restore(); err != nil {
+		slog.Error("failed to restore running games", "err", err)
+	}
+
+	manager, err := downloads.NewManager(
+		filepath.Join(stateDir, downloads.QUEUE_FILE_NAME),
+		cfg.GetMaxConcurrentDownloads(),
+		func(ctx context.Context, job downloads.Job, report func(downloads.Progress)) error {
+			g, ok := gamesByID[job.GameID]
+			if !ok {
+				return fmt.Errorf("game %s is no longer in the registry", job.GameName)
+			}
+			// Replacing files under a running game fails on some systems and
+			// corrupts it on others.
+			if _, ok := running.Running(g.ID); ok {
+				return fmt.Errorf("%w: stop %s first", supervisor.ErrAlreadyRunning, g.Name)
+			}
+			p, err := providers.For(g)
+			if err != nil {
+				return err
+			}
+
+			sioReport := func(pr sysio.Progress) {
+				report(downloads.Progress{Fraction: pr.Fraction(), Text: pr.String()})
+			}
+
+			switch job.Kind {
+			case downloads.JOB_REPAIR:
+				_, err := sio.RepairGame(ctx, p, g, sioReport)
+				return err
+			case downloads.JOB_MOVE:
+				return sio.MoveGame(ctx, g, job.Library, sioReport)
+			}
+
+			var fp *string
+			if job.Tag != "" {
+				fp, err = sio.DownloadRelease(ctx, p, g, job.Tag, sioReport)
+			} else {
+				fp, err = sio.DownloadLatestRelease(ctx, p, g, sioReport)
+			}
+			if err != nil {
+				return err
+			}
+			return sio.InstallLatestRelease(ctx, fp, g, sioReport)
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	// The task runner goes first so nothing is left waiting to post job
+	// updates to a game loop that has stopped.
+	defer func() {
+		tasks.Shutdown()
+		if err := manager.Close(); err != nil {
+			slog.Error("failed to save download queue", "err", err)
+		}
+	}()
+
+	ss.SetState("game", games[0])
+
+	versionLabel := label.NewLabel(
+		.34, .86,
+		20,
+		color.White,
+		"",
+		t,
+	)
+
+	checkGameButton := button.NewButton(
+		.28, .9,
+		.12, .07,
+		36,
+		color.RGBA{32, 96, 246, 255},
+		color.White,
+		"",
+		t,
+	)
+
+	installProgress := progressbar.NewProgressBar(
+		.28, .8,
+		.5, .02,
+		16,
+		color.RGBA{32, 96, 246, 255},
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		t,
+	)
+
+	rollbackButton := button.NewButton(
+		.7, .9,
+		.14, .07,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"",
+		t,
+	)
+	rollbackButton.SetState(button.STATE_CUSTOM)
+
+	rollbackView := view.NewView(rollbackButton)
+	rollbackView.SetHidden(true)
+
+	verifyButton := button.NewButton(
+		.86, .9,
+		.12, .07,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"",
+		t,
+	)
+	verifyButton.SetState(button.STATE_CUSTOM)
+
+	uninstallButton := button.NewButton(
+		.86, .81,
+		.12, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Uninstall",
+		t,
+	)
+	uninstallButton.SetState(button.STATE_CUSTOM)
+
+	moveButton := button.NewButton(
+		.74, .81,
+		.1, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Move",
+		t,
+	)
+	moveButton.SetState(button.STATE_CUSTOM)
+
+	launchOptionsButton := button.NewButton(
+		.86, .72,
+		.12, .06,
+		18,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Launch options",
+		t,
+	)
+	launchOptionsButton.SetState(button.STATE_CUSTOM)
+
+	// installedView holds the actions that only apply to installed games.
+	installedView := view.NewView(verifyButton, moveButton, uninstallButton, launchOptionsButton)
+	installedView.SetHidden(true)
+
+	// noticeLabel shows the outcome of the last action on the selected game.
+	noticeLabel := label.NewLabel(
+		.45, .78,
+		18,
+		color.White,
+		"",
+		t,
+	)
+
+	// targetButton picks the library folder a game that is not installed yet
+	// goes into.
+	targetButton := button.NewButton(
+		.28, .71,
+		.36, .05,
+		18,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"",
+		t,
+	)
+	targetButton.SetState(button.STATE_CUSTOM)
+
+	targetView := view.NewView(targetButton)
+	targetView.SetHidden(true)
+
+	// libraries is the list the target button cycles through, as of the
+	// last check.
+	var libraries []sysio.Library
+
+	targetIndex := func(g requests.Game) int {
+		target := cfg.Game(g.ID).Library
+		for i, l := range libraries {
+			if l.Path == target {
+				return i
+			}
+		}
+		return 0
+	}
+
+	setTargetText := func(g requests.Game) {
+		if len(libraries) == 0 {
+			return
+		}
+
+		l := libraries[targetIndex(g)]
+		free := "free space unknown"
+		if l.Free >= 0 {
+			free = sysio.FormatBytes(l.Free) + " free"
+		}
+		targetButton.SetText(fmt.Sprintf("Install to %s · %s", l.Path, free))
+	}
+
+	targetButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if len(libraries) == 0 {
+			return nil
+		}
+
+		next := libraries[(targetIndex(g)+1)%len(libraries)]
+
+		gc := cfg.Game(g.ID)
+		gc.Library = next.Path
+		if next.Default {
+			gc.Library = ""
+		}
+		if err = cfg.SetGame(g.ID, gc); err != nil {
+			noticeLabel.SetText(fmt.Sprintf("Failed to save settings: %v", err))
+			return nil
+		}
+
+		setTargetText(g)
+		return nil
+	})
+
+	// uninstallView replaces the library page while it asks for
+	// confirmation. It is set once the library page exists.
+	var uninstallView *uninstallDialog
+	// showMessage blocks the library page with a message. It is set once
+	// the library page exists.
+	var showMessage func(title, body string)
+
+	var checkTask *game.Task
+	var rollbackTo string
+	var verifyTask *game.Task
+	// needsRepair is set once a verify finds damaged files, turning the
+	// verify button into a repair button.
+	var needsRepair bool
+	// checkFailed is set when the last check could not finish, turning the
+	// game button into a retry button.
+	var checkFailed bool
+
+	checkGame := func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if checkTask != nil {
+			checkTask.Cancel()
+			checkTask = nil
+		}
+
+		b.SetState(button.STATE_CUSTOM)
+		checkFailed = false
+		rollbackView.SetHidden(true)
+		targetView.SetHidden(true)
+
+		if verifyTask != nil {
+			verifyTask.Cancel()
+			verifyTask = nil
+			installProgress.Finish()
+		}
+		needsRepair = false
+		verifyButton.SetText("Verify files")
+		installedView.SetHidden(true)
+
+		if p, ok := running.Running(g.ID); ok {
+			if p.Stopping {
+				b.SetText("Stopping")
+			} else {
+				b.SetState(button.STATE_RUNNING)
+			}
+			return nil
+		}
+
+		if j, ok := manager.Pending(g.ID); ok {
+			switch j.State {
+			case downloads.JOB_ACTIVE:
+				b.SetText(activeText(j))
+			case downloads.JOB_PAUSED:
+				b.SetText("Paused")
+			default:
+				b.SetText("Queued")
+			}
+			return nil
+		}
+
+		b.SetText("Checking")
+
+		var t *game.Task
+		t = tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			ok, err := sio.CheckForGame(g)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				libraries, err := sio.Libraries()
+				if err != nil {
+					return nil, err
+				}
+				return checkResult{libraries: libraries}, nil
+			}
+			kept, err := sio.ListVersions(g)
+			if err != nil {
+				return nil, err
+			}
+			p, err := providers.For(g)
+			if err != nil {
+				return nil, err
+			}
+			status, err := sio.CheckLatest(ctx, p, g)
+			if err != nil {
+				return nil, err
+			}
+			return checkResult{status: status, kept: kept}, nil
+		}, func(res interface{}, err error) error {
+			// A newer check superseded this one.
+			if t != checkTask {
+				return nil
+			}
+			// Being offline or a missing release should not close the
+			// launcher, so the failure is shown and can be retried.
+			if err != nil {
+				versionLabel.SetText("Check failed")
+				noticeLabel.SetText(fmt.Sprintf("Failed to check %s: %v", g.Name, err))
+				b.SetText("Retry")
+				checkFailed = true
+				return nil
+			}
+
+			check := res.(checkResult)
+			if check.status == nil {
+				versionLabel.SetText("")
+				libraries = check.libraries
+				setTargetText(g)
+				targetView.SetHidden(false)
+				b.SetState(button.STATE_INSTALL)
+				return nil
+			}
+
+			if len(check.kept) > 0 {
+				rollbackTo = check.kept[0].Version
+				rollbackButton.SetText(fmt.Sprintf("Roll back to %s", rollbackTo))
+				rollbackView.SetHidden(false)
+			}
+
+			installedView.SetHidden(false)
+
+			status := check.status
+			versionLabel.SetText(status.String())
+			if status.UpdateAvailable() {
+				b.SetState(button.STATE_UPDATE)
+			} else {
+				b.SetState(button.STATE_PLAY)
+			}
+			return nil
+		})
+		checkTask = t
+
+		return nil
+	}
+
+	checkGameButton.AddHandler(button.HANDLER_ON_MOUNT, checkGame)
+
+	// enqueueInstall checks that a release fits on disk before queueing it,
+	// so a short volume is reported up front instead of failing midway
+	// through the download. An empty tag queues the channel's release.
+	enqueueInstall := func(g requests.Game, kind downloads.JobKind, tag string) error {
+		if _, ok := manager.Pending(g.ID); ok {
+			return nil
+		}
+
+		p, err := providers.For(g)
+		if err != nil {
+			return err
+		}
+
+		checkGameButton.SetState(button.STATE_CUSTOM)
+		checkGameButton.SetText("Checking space")
+
+		tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			return nil, sio.CheckSpace(ctx, p, g, tag)
+		}, func(_ interface{}, err error) error {
+			var spaceErr *sysio.SpaceError
+			if errors.As(err, &spaceErr) {
+				showMessage(
+					"Not enough disk space",
+					fmt.Sprintf(
+						"%s needs %s on %s but only %s is free.",
+						g.Name,
+						sysio.FormatBytes(spaceErr.Needed),
+						spaceErr.Path,
+						sysio.FormatBytes(spaceErr.Free),
+					),
+				)
+				return checkGame(checkGameButton)
+			}
+
+			// Any other failure, like being offline, is reported by the
+			// job itself.
+			if tag != "" {
+				_, err = manager.EnqueueRelease(g.ID, g.Name, tag, 0)
+			} else {
+				_, err = manager.Enqueue(g.ID, g.Name, kind, 0)
+			}
+			if err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to queue %s: %v", g.Name, err))
+			}
+			return checkGame(checkGameButton)
+		})
+
+		return nil
+	}
+	checkGameButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		switch b.GetState() {
+		case button.STATE_PLAY:
+			path, err := sio.GetInstallDirPath(g)
+			if err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to launch %s: %v", g.Name, err))
+				return nil
+			}
+
+			cmd, err := sio.GameCommand(path, g)
+			if err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to launch %s: %v", g.Name, err))
+				return nil
+			}
+
+			if _, err = running.Start(g.ID, cmd); err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to launch %s: %v", g.Name, err))
+				return nil
+			}
+			return checkGame(b)
+		case button.STATE_RUNNING:
+			if err := running.Stop(g.ID); err != nil && !errors.Is(err, supervisor.ErrNotRunning) {
+				noticeLabel.SetText(fmt.Sprintf("Failed to stop %s: %v", g.Name, err))
+				return nil
+			}
+			return checkGame(b)
+		case button.STATE_INSTALL:
+			return enqueueInstall(g, downloads.JOB_INSTALL, "")
+		case button.STATE_UPDATE:
+			return enqueueInstall(g, downloads.JOB_UPDATE, "")
+		default:
+			if checkFailed {
+				return checkGame(b)
+			}
+		}
+
+		return nil
+	})
+
+	// reloadReleases refreshes the releases page when it is showing. It is
+	// set once the page exists.
+	var reloadReleases func() error
+
+	rollback := func(version string) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		// Installs swap the same directories, so wait for them to finish.
+		if _, ok := manager.Pending(g.ID); ok {
+			return nil
+		}
+		if _, ok := running.Running(g.ID); ok {
+			noticeLabel.SetText(fmt.Sprintf("Stop %s before rolling back", g.Name))
+			return nil
+		}
+
+		if checkTask != nil {
+			checkTask.Cancel()
+			checkTask = nil
+		}
+
+		rollbackView.SetHidden(true)
+		checkGameButton.SetState(button.STATE_CUSTOM)
+		checkGameButton.SetText("Rolling back")
+
+		tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			return nil, sio.RollbackVersion(g, version)
+		}, func(_ interface{}, err error) error {
+			// A failed rollback leaves the installed version in place.
+			if err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Rollback failed: %v", err))
+			} else {
+				noticeLabel.SetText(fmt.Sprintf("Rolled back to %s", version))
+			}
+			if err := reloadReleases(); err != nil {
+				return err
+			}
+			return checkGame(checkGameButton)
+		})
+
+		return nil
+	}
+
+	rollbackButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return rollback(rollbackTo)
+	})
+
+	verifyButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if _, ok := manager.Pending(g.ID); ok || verifyTask != nil {
+			return nil
+		}
+
+		if needsRepair {
+			if _, err := manager.Enqueue(g.ID, g.Name, downloads.JOB_REPAIR, 0); err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to queue %s: %v", g.Name, err))
+			}
+			return checkGame(checkGameButton)
+		}
+
+		b.SetText("Verifying")
+
+		var t *game.Task
+		t = tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			return sio.VerifyGame(ctx, g, func(pr sysio.Progress) {
+				installProgress.Send(progressbar.Report{Fraction: pr.Fraction(), Text: pr.String()})
+			})
+		}, func(res interface{}, err error) error {
+			if t != verifyTask {
+				return nil
+			}
+			verifyTask = nil
+			installProgress.Finish()
+
+			if err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Verify failed: %v", err))
+				b.SetText("Verify files")
+				return nil
+			}
+
+			report := res.(*sysio.VerifyReport)
+			noticeLabel.SetText(report.String())
+			if report.OK() {
+				b.SetText("Verify files")
+			} else {
+				needsRepair = true
+				b.SetText("Repair")
+			}
+			return nil
+		})
+		verifyTask = t
+
+		return nil
+	})
+
+	channelButton := button.NewButton(
+		.42, .9,
+		.12, .07,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"",
+		t,
+	)
+	channelButton.SetState(button.STATE_CUSTOM)
+
+	setChannelText := func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		gc := cfg.Game(g.ID)
+		if gc.Channel == config.CHANNEL_PINNED {
+			b.SetText(fmt.Sprintf("Pinned: %s", gc.PinnedTag))
+		} else {
+			b.SetText(fmt.Sprintf("Channel: %s", gc.Channel))
+		}
+		return nil
+	}
+
+	channelButton.AddHandler(button.HANDLER_ON_MOUNT, setChannelText)
+	channelButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		// Pinning needs a tag, so it is only part of the cycle once a release
+		// has been pinned from the releases page.
+		gc := cfg.Game(g.ID)
+		switch gc.Channel {
+		case config.CHANNEL_STABLE:
+			gc.Channel = config.CHANNEL_BETA
+		case config.CHANNEL_BETA:
+			if gc.PinnedTag != "" {
+				gc.Channel = config.CHANNEL_PINNED
+			} else {
+				gc.Channel = config.CHANNEL_STABLE
+			}
+		default:
+			gc.Channel = config.CHANNEL_STABLE
+		}
+
+		if err = cfg.SetGame(g.ID, gc); err != nil {
+			noticeLabel.SetText(fmt.Sprintf("Failed to save settings: %v", err))
+			return nil
+		}
+		if err = setChannelText(b); err != nil {
+			return err
+		}
+		return checkGame(checkGameButton)
+	})
+
+	options := make([]drawer.Option, 0)
+
+	for _, v := range games {
+		var img *ebiten.Image
+
+		if v.IconURL != "" {
+			img, err = ebitenImageFromURL(v.IconURL)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		options = append(options, drawer.NewOption(v.Name, img))
+	}
+	gamesDrawer := drawer.NewDrawer(
+		0, 0.2,
+		.25, .8,
+		0.07,
+		32,
+		options,
+		color.RGBA{42, 42, 42, 255},
+		color.White,
+		t,
+	)
+
+	releasesView := newReleasesPage(
+		t,
+		func(tag string) error {
+			s, err := ss.GetState("game")
+			if err != nil {
+				return err
+			}
+			g, ok := s.(requests.Game)
+			if !ok {
+				return fmt.Errorf("failed to convert state to Game")
+			}
+
+			return enqueueInstall(g, downloads.JOB_REINSTALL, tag)
+		},
+		rollback,
+		func(tag string) error {
+			s, err := ss.GetState("game")
+			if err != nil {
+				return err
+			}
+			g, ok := s.(requests.Game)
+			if !ok {
+				return fmt.Errorf("failed to convert state to Game")
+			}
+
+			// Unpinning keeps the tag so the channel button can cycle back
+			// to it.
+			gc := cfg.Game(g.ID)
+			if tag == "" {
+				gc.Channel = config.CHANNEL_STABLE
+			} else {
+				gc.Channel = config.CHANNEL_PINNED
+				gc.PinnedTag = tag
+			}
+
+			if err = cfg.SetGame(g.ID, gc); err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to save settings: %v", err))
+				return nil
+			}
+			if err = setChannelText(channelButton); err != nil {
+				return err
+			}
+			if err = reloadReleases(); err != nil {
+				return err
+			}
+			return checkGame(checkGameButton)
+		},
+	)
+	releasesView.SetHidden(true)
+
+	var releasesTask *game.Task
 
-	games, err := client.GetGames()
-	if err != nil {
-		panic(err)
-	}
+	loadReleases := func() error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
 
-	gClient := github.NewClient(nil)
+		if releasesTask != nil {
+			releasesTask.Cancel()
+		}
 
-	release, resp, err := gClient.Repositories.GetLatestRelease(context.Background(), games[0].RepoOwner, games[0].RepoName)
-	if err != nil {
-		panic(fmt.Sprintf("err: %+v, resp: %+v", err, resp))
-	}
+		releasesView.SetReleases(g.Name, nil, nil, "")
 
-	for _, v := range release.Assets {
-		url := v.BrowserDownloadURL
-		if url == nil {
-			panic("failed to find release url")
+		type gameReleases struct {
+			kept     []sysio.InstalledVersion
+			releases []provider.Release
 		}
-	}
 
-	t, err := newTxtRenderer()
-	if err != nil {
-		slog.Error("failed to create txt renderer", "err", err)
-		return
+		var t *game.Task
+		t = tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			kept, err := sio.ListVersions(g)
+			if err != nil {
+				return nil, err
+			}
+			p, err := providers.For(g)
+			if err != nil {
+				return nil, err
+			}
+			releases, err := p.ListReleases(ctx, g)
+			if err != nil {
+				return nil, err
+			}
+			return gameReleases{kept: kept, releases: releases}, nil
+		}, func(res interface{}, err error) error {
+			if t != releasesTask {
+				return nil
+			}
+			if err != nil {
+				releasesView.SetError(err)
+				return nil
+			}
+
+			r := res.(gameReleases)
+			releasesView.SetReleases(g.Name, r.kept, r.releases, pinnedTag(cfg.Game(g.ID)))
+			return nil
+		})
+		releasesTask = t
+
+		return nil
 	}
 
-	sio, err := sysio.NewSysio()
-	if err != nil {
-		panic(err)
+	reloadReleases = func() error {
+		if releasesView.IsHidden() {
+			return nil
+		}
+		return loadReleases()
 	}
 
-	ss := game.NewStateStore()
+	releasesButton := button.NewButton(
+		.56, .9,
+		.12, .07,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Releases",
+		t,
+	)
+	releasesButton.SetState(button.STATE_CUSTOM)
 
-	ss.SetState("game", games[0])
+	navButton := button.NewButton(
+		.86, .03,
+		.12, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Downloads",
+		t,
+	)
+	navButton.SetState(button.STATE_CUSTOM)
 
-	checkGameButton := button.NewButton(
-		.28, .9,
-		.12, .07,
-		36,
-		color.RGBA{32, 96, 246, 255},
+	logsButton := button.NewButton(
+		.72, .03,
+		.12, .06,
+		20,
+		color.RGBA{64, 64, 64, 255},
 		color.White,
-		"",
+		"Logs",
+		t,
+	)
+	logsButton.SetState(button.STATE_CUSTOM)
+	libraryView := view.NewView(
+		checkGameButton,
+		logsButton,
+		channelButton,
+		releasesButton,
+		rollbackView,
+		targetView,
+		installedView,
+		installProgress,
+		versionLabel,
+		noticeLabel,
+	)
+
+	uninstallView = newUninstallDialog(
+		t,
+		func(g requests.Game, removeUserData bool) error {
+			uninstallView.SetHidden(true)
+			libraryView.SetHidden(false)
+
+			if _, ok := manager.Pending(g.ID); ok {
+				return nil
+			}
+			// The game may have been started while the dialog was open.
+			if _, ok := running.Running(g.ID); ok {
+				noticeLabel.SetText(fmt.Sprintf("Stop %s before uninstalling", g.Name))
+				return nil
+			}
+
+			if checkTask != nil {
+				checkTask.Cancel()
+				checkTask = nil
+			}
+			checkGameButton.SetState(button.STATE_CUSTOM)
+			checkGameButton.SetText("Removing")
+			installedView.SetHidden(true)
+			rollbackView.SetHidden(true)
+
+			tasks.Submit(func(ctx context.Context) (interface{}, error) {
+				return nil, sio.Uninstall(g, sysio.UninstallOptions{RemoveUserData: removeUserData})
+			}, func(_ interface{}, err error) error {
+				if err != nil {
+					noticeLabel.SetText(fmt.Sprintf("Uninstall failed: %v", err))
+				} else {
+					noticeLabel.SetText(fmt.Sprintf("Uninstalled %s", g.Name))
+				}
+				if err := reloadReleases(); err != nil {
+					return err
+				}
+				return checkGame(checkGameButton)
+			})
+
+			return nil
+		},
+		func() error {
+			uninstallView.SetHidden(true)
+			libraryView.SetHidden(false)
+			return nil
+		},
+	)
+
+	var launchOptionsView *launchOptionsDialog
+	launchOptionsView = newLaunchOptionsDialog(
 		t,
+		func(g requests.Game, args []string, env map[string]string, dir string) error {
+			launchOptionsView.SetHidden(true)
+			libraryView.SetHidden(false)
+
+			gc := cfg.Game(g.ID)
+			gc.LaunchArgs = args
+			gc.LaunchEnv = env
+			if len(env) == 0 {
+				gc.LaunchEnv = nil
+			}
+			gc.LaunchDir = dir
+
+			if err := cfg.SetGame(g.ID, gc); err != nil {
+				noticeLabel.SetText(fmt.Sprintf("Failed to save settings: %v", err))
+				return nil
+			}
+			noticeLabel.SetText(fmt.Sprintf("Saved launch options for %s", g.Name))
+			return nil
+		},
+		func() error {
+			launchOptionsView.SetHidden(true)
+			libraryView.SetHidden(false)
+			return nil
+		},
 	)
 
-	checkGameButton.AddHandler(button.HANDLER_ON_MOUNT, func(b *button.Button) error {
+	launchOptionsButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
 		s, err := ss.GetState("game")
 		if err != nil {
 			return err
@@ -80,26 +1032,107 @@ func main() {
 		if !ok {
 			return fmt.Errorf("failed to convert state to Game")
 		}
-		ok, err = sio.CheckForGame(g)
+
+		libraryView.SetHidden(true)
+		launchOptionsView.Show(g, cfg.Game(g.ID))
+		return nil
+	})
+
+	var messageView *messageDialog
+	messageView = newMessageDialog(t, func() error {
+		messageView.SetHidden(true)
+		libraryView.SetHidden(false)
+		return nil
+	})
+	showMessage = func(title, body string) {
+		releasesView.SetHidden(true)
+		libraryView.SetHidden(true)
+		messageView.Show(title, body)
+		navButton.SetText("Library")
+	}
+
+	var logsView *logsPage
+	var logsTask *game.Task
+
+	loadLogs := func() error {
+		s, err := ss.GetState("game")
 		if err != nil {
 			return err
 		}
-		if ok {
-			latest, err := sio.CheckLatest(gClient, g)
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if logsTask != nil {
+			logsTask.Cancel()
+		}
+
+		type gameLog struct {
+			sessions []gamelog.Session
+			path     string
+			lines    []string
+		}
+
+		dir := running.LogDir(g.ID)
+		selected := logsView.Selected()
+
+		var t *game.Task
+		t = tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			sessions, err := gamelog.Sessions(dir)
+			if err != nil || len(sessions) == 0 {
+				return gameLog{}, err
+			}
+
+			path := sessions[0].Path
+			for _, s := range sessions {
+				if s.Path == selected {
+					path = selected
+				}
+			}
+
+			lines, err := gamelog.ReadTail(path, LOG_TAIL_BYTES)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			if latest {
-				b.SetState(button.STATE_PLAY)
-			} else {
-				b.SetState(button.STATE_UPDATE)
+			return gameLog{sessions: sessions, path: path, lines: lines}, nil
+		}, func(res interface{}, err error) error {
+			if t != logsTask {
+				return nil
 			}
-		} else {
-			b.SetState(button.STATE_INSTALL)
+			logsTask = nil
+			if err != nil {
+				logsView.SetError(err)
+				return nil
+			}
+
+			l := res.(gameLog)
+			logsView.SetLog(l.sessions, l.path, l.lines)
+			return nil
+		})
+		logsTask = t
+
+		return nil
+	}
+
+	logsView = newLogsPage(t, loadLogs, func() error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if err = sio.OpenFolder(running.LogDir(g.ID)); err != nil {
+			logsView.SetError(err)
 		}
 		return nil
 	})
-	checkGameButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+	logsView.SetHidden(true)
+
+	logsButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
 		s, err := ss.GetState("game")
 		if err != nil {
 			return err
@@ -109,66 +1142,126 @@ func main() {
 			return fmt.Errorf("failed to convert state to Game")
 		}
 
-		switch b.GetState() {
-		case button.STATE_PLAY:
-			path, err := sio.GetInstallDirPath()
-			if err != nil {
-				return err
-			}
+		libraryView.SetHidden(true)
+		logsView.Reset(g.Name)
+		logsView.SetHidden(false)
+		navButton.SetText("Library")
 
-			if err = sio.ExecuteGame(path, g); err != nil {
-				return err
-			}
-		case button.STATE_INSTALL:
-			fallthrough
-		case button.STATE_UPDATE:
-			fp, err := sio.DownloadLatestRelease(gClient, g)
-			if err != nil {
-				return err
-			}
-			if err = sio.InstallLatestRelease(fp, g); err != nil {
-				return err
-			}
-		default:
+		return loadLogs()
+	})
+
+	var moveView *movePage
+	moveView = newMovePage(t, func(library string) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		moveView.SetHidden(true)
+		libraryView.SetHidden(false)
+		navButton.SetText("Downloads")
+
+		if _, ok := running.Running(g.ID); ok {
+			noticeLabel.SetText(fmt.Sprintf("Stop %s before moving it", g.Name))
+			return nil
 		}
 
-		ok, err = sio.CheckForGame(g)
+		if _, err := manager.EnqueueMove(g.ID, g.Name, library, 0); err != nil {
+			noticeLabel.SetText(fmt.Sprintf("Failed to queue %s: %v", g.Name, err))
+		}
+		return checkGame(checkGameButton)
+	})
+	moveView.SetHidden(true)
+
+	moveButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
 		if err != nil {
 			return err
 		}
-		if ok {
-			// checkLatest("MacOS")
-			b.SetState(button.STATE_PLAY)
-		} else {
-			b.SetState(button.STATE_INSTALL)
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
+
+		if _, ok := manager.Pending(g.ID); ok {
+			return nil
+		}
+		if _, ok := running.Running(g.ID); ok {
+			noticeLabel.SetText(fmt.Sprintf("Stop %s before moving it", g.Name))
+			return nil
+		}
+
+		libraryView.SetHidden(true)
+		moveView.SetHidden(false)
+		moveView.SetLibraries(g.Name, "", nil)
+		navButton.SetText("Library")
+
+		type gameLibraries struct {
+			current   string
+			libraries []sysio.Library
 		}
+
+		tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			current, err := sio.GetInstallDirPath(g)
+			if err != nil {
+				return nil, err
+			}
+			libraries, err := sio.Libraries()
+			if err != nil {
+				return nil, err
+			}
+			return gameLibraries{current: current, libraries: libraries}, nil
+		}, func(res interface{}, err error) error {
+			if err != nil {
+				moveView.SetError(err)
+				return nil
+			}
+
+			r := res.(gameLibraries)
+			moveView.SetLibraries(g.Name, r.current, r.libraries)
+			return nil
+		})
+
 		return nil
 	})
 
-	options := make([]drawer.Option, 0)
+	uninstallButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		s, err := ss.GetState("game")
+		if err != nil {
+			return err
+		}
+		g, ok := s.(requests.Game)
+		if !ok {
+			return fmt.Errorf("failed to convert state to Game")
+		}
 
-	for _, v := range games {
-		var img *ebiten.Image
+		if _, ok := manager.Pending(g.ID); ok {
+			return nil
+		}
+		if _, ok := running.Running(g.ID); ok {
+			noticeLabel.SetText(fmt.Sprintf("Stop %s before uninstalling", g.Name))
+			return nil
+		}
 
-		if v.IconURL != "" {
-			img, err = ebitenImageFromURL(v.IconURL)
+		tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			return sio.UserDataDirs(g)
+		}, func(res interface{}, err error) error {
 			if err != nil {
-				panic(err)
+				noticeLabel.SetText(fmt.Sprintf("Uninstall failed: %v", err))
+				return nil
 			}
-		}
 
-		options = append(options, drawer.NewOption(v.Name, img))
-	}
-	gamesDrawer := drawer.NewDrawer(
-		0, 0.2,
-		.25, .8,
-		0.07,
-		32,
-		options,
-		color.RGBA{42, 42, 42, 255},
-		color.White,
-		t,
-	)
+			libraryView.SetHidden(true)
+			uninstallView.Show(g, res.([]string))
+			return nil
+		})
+
+		return nil
+	})
 
 	gamesDrawer.AddHandler(drawer.HANDLER_ON_CLICK, func(d *drawer.Drawer) error {
 		for _, v := range games {
@@ -177,14 +1270,153 @@ func main() {
 				break
 			}
 		}
+		if !uninstallView.IsHidden() || !moveView.IsHidden() || !messageView.IsHidden() || !launchOptionsView.IsHidden() {
+			uninstallView.SetHidden(true)
+			moveView.SetHidden(true)
+			messageView.SetHidden(true)
+			launchOptionsView.SetHidden(true)
+			libraryView.SetHidden(false)
+			navButton.SetText("Downloads")
+		}
+		noticeLabel.SetText("")
+
+		if !logsView.IsHidden() {
+			logsView.Reset(d.GetSelection().GetText())
+			if err := loadLogs(); err != nil {
+				return err
+			}
+		}
+
+		if err := setChannelText(channelButton); err != nil {
+			return err
+		}
+		if err := reloadReleases(); err != nil {
+			return err
+		}
+		return checkGame(checkGameButton)
+	})
+
+	var downloadsView *downloadsPage
+	var clearTask *game.Task
+	downloadsView = newDownloadsPage(manager, t, func() error {
+		if clearTask != nil {
+			return nil
+		}
+
+		downloadsView.SetNotice("Clearing download cache")
+		clearTask = tasks.Submit(func(ctx context.Context) (interface{}, error) {
+			return sio.ClearDownloadCache()
+		}, func(res interface{}, err error) error {
+			clearTask = nil
+
+			switch {
+			case errors.Is(err, sysio.ErrCacheInUse):
+				downloadsView.SetNotice("Wait for downloads to finish before clearing the cache")
+			case err != nil:
+				downloadsView.SetNotice(fmt.Sprintf("Clearing the cache failed: %v", err))
+			default:
+				downloadsView.SetNotice(fmt.Sprintf("Cleared %s from the download cache", sysio.FormatBytes(res.(int64))))
+			}
+			return nil
+		})
+
+		return nil
+	})
+	downloadsView.SetHidden(true)
+
+	navButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		showDownloads := !libraryView.IsHidden()
+
+		downloadsView.SetHidden(!showDownloads)
+		releasesView.SetHidden(true)
+		uninstallView.SetHidden(true)
+		moveView.SetHidden(true)
+		messageView.SetHidden(true)
+		launchOptionsView.SetHidden(true)
+		logsView.SetHidden(true)
+		libraryView.SetHidden(showDownloads)
+
+		if showDownloads {
+			downloadsView.Refresh()
+			b.SetText("Library")
+		} else {
+			b.SetText("Downloads")
+		}
 		return nil
 	})
 
+	releasesButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		libraryView.SetHidden(true)
+		releasesView.SetHidden(false)
+		navButton.SetText("Library")
+
+		return loadReleases()
+	})
+
+	manager.SetOnChange(func(j downloads.Job) {
+		tasks.Post(func() error {
+			downloadsView.Refresh()
+
+			s, err := ss.GetState("game")
+			if err != nil {
+				return err
+			}
+			if g, ok := s.(requests.Game); !ok || g.ID != j.GameID {
+				return nil
+			}
+
+			if j.State == downloads.JOB_ACTIVE {
+				installProgress.Send(progressbar.Report{
+					Fraction: j.Progress.Fraction,
+					Text:     j.Progress.Text,
+				})
+				if checkGameButton.GetState() == button.STATE_CUSTOM {
+					checkGameButton.SetText(activeText(j))
+				}
+				return nil
+			}
+
+			installProgress.Finish()
+			if j.State == downloads.JOB_DONE {
+				if err := reloadReleases(); err != nil {
+					return err
+				}
+			}
+			return checkGame(checkGameButton)
+		})
+	})
+	manager.Start()
+
+	running.SetOnEvent(func(e supervisor.Event) {
+		tasks.Post(func() error {
+			s, err := ss.GetState("game")
+			if err != nil {
+				return err
+			}
+			if g, ok := s.(requests.Game); !ok || g.ID != e.GameID {
+				return nil
+			}
+
+			if e.Kind == supervisor.EVENT_CRASHED {
+				noticeLabel.SetText(fmt.Sprintf("%s crashed: %s · see Logs", gamesByID[e.GameID].Name, e.Err))
+			}
+			return checkGame(checkGameButton)
+		})
+	})
+
 	d := []game.Drawable{
 		panel.NewPanel(0, 0, 1, 1, color.RGBA{42, 42, 42, 255}),
 		gamesDrawer,
 		panel.NewPanel(0.25, 0, .75, 1, color.RGBA{32, 32, 32, 255}),
-		checkGameButton,
+		libraryView,
+		uninstallView,
+		moveView,
+		messageView,
+		launchOptionsView,
+		logsView,
+		downloadsView,
+		releasesView,
+		navButton,
 		label.NewLabel(
 			0.1, 0.1,
 			36,
@@ -194,7 +1426,7 @@ func main() {
 		),
 	}
 
-	g := game.NewGame(t, d, ss)
+	g := game.NewGame(t, d, ss, tasks)
 
 	if err := ebiten.RunGame(g); err != nil {
 		slog.Error("closing game", "err", err)
@@ -202,6 +1434,15 @@ func main() {
 	}
 }
 
+// checkResult is what a background game check hands back to the UI.
+type checkResult struct {
+	// status is nil when the game is not installed, in which case
+	// libraries lists where it could go.
+	status    *sysio.UpdateStatus
+	kept      []sysio.InstalledVersion
+	libraries []sysio.Library
+}
+
 func newTxtRenderer() (*etxt.Renderer, error) {
 	robotoFont := fonts.F
 
@@ -231,3 +1472,13 @@ func ebitenImageFromURL(url string) (*ebiten.Image, error) {
 	}
 	return ebiten.NewImageFromImage(img), nil
 }
+
+// pinnedTag is the tag gc pins its game to, or empty when it follows a
+// channel.
+func pinnedTag(gc config.GameConfig) string {
+	if gc.Channel != config.CHANNEL_PINNED {
+		return ""
+	}
+
+	return gc.PinnedTag
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/panel"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+// messageDialog blocks the library page with a message until it is
+// dismissed.
+type messageDialog struct {
+	*view.View
+	title *label.Label
+	body  *label.Label
+}
+
+func newMessageDialog(t *etxt.Renderer, dismiss func() error) *messageDialog {
+	d := &messageDialog{
+		title: label.NewLabel(
+			.6, .4,
+			28,
+			color.White,
+			"",
+			t,
+		),
+		body: label.NewLabel(
+			.6, .47,
+			18,
+			color.White,
+			"",
+			t,
+		),
+	}
+
+	okButton := button.NewButton(
+		.72, .55,
+		.1, .07,
+		20,
+		color.RGBA{32, 96, 246, 255},
+		color.White,
+		"OK",
+		t,
+	)
+	okButton.SetState(button.STATE_CUSTOM)
+	okButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return dismiss()
+	})
+
+	d.View = view.NewView(
+		panel.NewPanel(.35, .3, .5, .36, color.RGBA{48, 48, 48, 255}),
+		d.title,
+		d.body,
+		okButton,
+	)
+	d.SetHidden(true)
+
+	return d
+}
+
+// Show displays title and body.
+func (d *messageDialog) Show(title, body string) {
+	d.title.SetText(title)
+	d.body.SetText(body)
+	d.SetHidden(false)
+}
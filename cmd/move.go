@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/sysio"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/list"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	ACTION_MOVE_HERE = "Move here"
+)
+
+// movePage lists the library folders with their free space so an installed
+// game can be moved into another one.
+type movePage struct {
+	*view.View
+	title     *label.Label
+	list      *list.List
+	libraries []sysio.Library
+	current   string
+}
+
+func newMovePage(t *etxt.Renderer, move func(library string) error) *movePage {
+	p := &movePage{
+		title: label.NewLabel(
+			.4, .1,
+			36,
+			color.White,
+			"Move Install",
+			t,
+		),
+		list: list.NewList(
+			.27, .2,
+			.71, .75,
+			.07,
+			18,
+			color.RGBA{32, 32, 32, 255},
+			color.RGBA{32, 96, 246, 255},
+			color.White,
+			t,
+		),
+	}
+
+	p.list.AddHandler(list.HANDLER_ON_ACTION, func(l *list.List, row, action int) error {
+		if row >= len(p.libraries) || p.libraries[row].Path == p.current {
+			return nil
+		}
+
+		return move(p.libraries[row].Path)
+	})
+
+	p.View = view.NewView(
+		p.title,
+		p.list,
+	)
+
+	return p
+}
+
+// SetLibraries replaces the rows. current is the library the game is in now.
+// It must be called from the update goroutine.
+func (p *movePage) SetLibraries(gameName, current string, libraries []sysio.Library) {
+	p.libraries = libraries
+	p.current = current
+
+	p.title.SetText(fmt.Sprintf("Move %s", gameName))
+
+	rows := make([]list.Row, 0, len(libraries))
+	for _, l := range libraries {
+		s := l.Path
+		if l.Free >= 0 {
+			s += " · " + sysio.FormatBytes(l.Free) + " free"
+		}
+
+		var actions []string
+		if l.Path == current {
+			s += " · installed here"
+		} else {
+			actions = []string{ACTION_MOVE_HERE}
+		}
+
+		rows = append(rows, list.Row{
+			Text:    s,
+			Actions: actions,
+		})
+	}
+
+	p.list.SetRows(rows)
+}
+
+// SetError shows why the libraries could not be listed.
+func (p *movePage) SetError(err error) {
+	p.libraries = nil
+
+	p.title.SetText(fmt.Sprintf("Failed to list libraries: %v", err))
+	p.list.SetRows(nil)
+}
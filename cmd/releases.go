@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/sysio"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/list"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	ACTION_INSTALL  = "Install"
+	ACTION_ROLLBACK = "Roll back"
+	ACTION_PIN      = "Pin"
+	ACTION_UNPIN    = "Unpin"
+)
+
+// releasesPage lists the versions of the selected game kept on disk and the
+// releases its provider offers, so any of them can be put back in place.
+type releasesPage struct {
+	*view.View
+	title    *label.Label
+	list     *list.List
+	kept     []sysio.InstalledVersion
+	releases []provider.Release
+	pinned   string
+}
+
+func newReleasesPage(
+	t *etxt.Renderer,
+	install func(tag string) error,
+	rollback func(version string) error,
+	pin func(tag string) error,
+) *releasesPage {
+	p := &releasesPage{
+		title: label.NewLabel(
+			.4, .1,
+			36,
+			color.White,
+			"Releases",
+			t,
+		),
+		list: list.NewList(
+			.27, .2,
+			.71, .75,
+			.07,
+			18,
+			color.RGBA{32, 32, 32, 255},
+			color.RGBA{32, 96, 246, 255},
+			color.White,
+			t,
+		),
+	}
+
+	p.list.AddHandler(list.HANDLER_ON_ACTION, func(l *list.List, row, action int) error {
+		if row < len(p.kept) {
+			return rollback(p.kept[row].Version)
+		}
+
+		row -= len(p.kept)
+		if row >= len(p.releases) {
+			return nil
+		}
+		r := p.releases[row]
+
+		switch p.releaseActions(r)[action] {
+		case ACTION_PIN:
+			return pin(r.Tag)
+		case ACTION_UNPIN:
+			return pin("")
+		default:
+			return install(r.Tag)
+		}
+	})
+
+	p.View = view.NewView(
+		p.title,
+		p.list,
+	)
+
+	return p
+}
+
+// SetReleases replaces the rows. pinned is the tag the game is pinned to, if
+// any. It must be called from the update goroutine.
+func (p *releasesPage) SetReleases(gameName string, kept []sysio.InstalledVersion, releases []provider.Release, pinned string) {
+	p.kept = kept
+	p.releases = releases
+	p.pinned = pinned
+
+	p.title.SetText(fmt.Sprintf("%s Releases", gameName))
+
+	rows := make([]list.Row, 0, len(kept)+len(releases))
+	for _, v := range kept {
+		rows = append(rows, list.Row{
+			Text:    fmt.Sprintf("%s · kept on disk · %s", v.Version, sysio.FormatBytes(v.Size)),
+			Actions: []string{ACTION_ROLLBACK},
+		})
+	}
+	for _, r := range releases {
+		text := releaseText(r)
+		if r.Tag == pinned {
+			text += " · pinned"
+		}
+
+		rows = append(rows, list.Row{
+			Text:    text,
+			Actions: p.releaseActions(r),
+		})
+	}
+
+	p.list.SetRows(rows)
+}
+
+// releaseActions pins a release, or unpins the one the game is pinned to,
+// next to installing it.
+func (p *releasesPage) releaseActions(r provider.Release) []string {
+	if r.Tag == p.pinned {
+		return []string{ACTION_UNPIN, ACTION_INSTALL}
+	}
+
+	return []string{ACTION_PIN, ACTION_INSTALL}
+}
+
+// SetError shows why the releases could not be loaded.
+func (p *releasesPage) SetError(err error) {
+	p.kept = nil
+	p.releases = nil
+
+	p.title.SetText(fmt.Sprintf("Failed to load releases: %v", err))
+	p.list.SetRows(nil)
+}
+
+func releaseText(r provider.Release) string {
+	s := r.Tag
+	if r.Name != "" && r.Name != r.Tag {
+		s += " · " + r.Name
+	}
+	if !r.PublishedAt.IsZero() {
+		s += " · " + r.PublishedAt.Format("2006-01-02")
+	}
+	if r.Prerelease {
+		s += " · prerelease"
+	}
+
+	return s
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/button"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/label"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/panel"
+	"github.com/DillonEnge/keizai-launcher/internal/ui/view"
+	"github.com/tinne26/etxt"
+)
+
+// uninstallDialog asks for confirmation before a game is removed and whether
+// its saves and settings should go with it. The dirs that would be deleted
+// are listed so nothing is removed that the user has not seen.
+type uninstallDialog struct {
+	*view.View
+	message  *label.Label
+	dataDirs *label.Label
+	game     requests.Game
+	dirs     []string
+}
+
+func newUninstallDialog(
+	t *etxt.Renderer,
+	confirm func(g requests.Game, removeUserData bool) error,
+	cancel func() error,
+) *uninstallDialog {
+	d := &uninstallDialog{
+		message: label.NewLabel(
+			.6, .36,
+			28,
+			color.White,
+			"",
+			t,
+		),
+		dataDirs: label.NewLabel(
+			.6, .45,
+			16,
+			color.White,
+			"",
+			t,
+		),
+	}
+
+	uninstallButton := button.NewButton(
+		.38, .55,
+		.13, .07,
+		20,
+		color.RGBA{32, 96, 246, 255},
+		color.White,
+		"Uninstall",
+		t,
+	)
+	uninstallButton.SetState(button.STATE_CUSTOM)
+	uninstallButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return confirm(d.game, false)
+	})
+
+	removeDataButton := button.NewButton(
+		.53, .55,
+		.17, .07,
+		20,
+		color.RGBA{196, 48, 48, 255},
+		color.White,
+		"Uninstall and delete saves",
+		t,
+	)
+	removeDataButton.SetState(button.STATE_CUSTOM)
+	removeDataButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		if len(d.dirs) == 0 {
+			return nil
+		}
+		return confirm(d.game, true)
+	})
+
+	cancelButton := button.NewButton(
+		.72, .55,
+		.1, .07,
+		20,
+		color.RGBA{64, 64, 64, 255},
+		color.White,
+		"Cancel",
+		t,
+	)
+	cancelButton.SetState(button.STATE_CUSTOM)
+	cancelButton.AddHandler(button.HANDLER_ON_CLICK, func(b *button.Button) error {
+		return cancel()
+	})
+
+	d.View = view.NewView(
+		panel.NewPanel(.35, .3, .5, .36, color.RGBA{48, 48, 48, 255}),
+		d.message,
+		d.dataDirs,
+		uninstallButton,
+		removeDataButton,
+		cancelButton,
+	)
+	d.SetHidden(true)
+
+	return d
+}
+
+// Show asks about g. dirs are the saves and settings dirs deleting its
+// saves would remove.
+func (d *uninstallDialog) Show(g requests.Game, dirs []string) {
+	d.game = g
+	d.dirs = dirs
+	d.message.SetText(fmt.Sprintf("Uninstall %s?", g.Name))
+	if len(dirs) > 0 {
+		d.dataDirs.SetText("Saves and settings are kept unless you choose to delete:\n" + strings.Join(dirs, "\n"))
+	} else {
+		d.dataDirs.SetText(fmt.Sprintf("%s has no saves or settings the launcher can delete.", g.Name))
+	}
+	d.SetHidden(false)
+}
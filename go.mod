@@ -2,28 +2,32 @@ module github.com/DillonEnge/keizai-launcher
 
 go 1.22.0
 
+require (
+	github.com/bi-zone/go-fileversion v1.0.0
+	github.com/google/go-github/v62 v62.0.0
+	github.com/hajimehoshi/ebiten/v2 v2.7.5
+	github.com/klauspost/compress v1.17.9
+	github.com/tinne26/etxt v0.0.8
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/sys v0.20.0
+	howett.net/plist v1.0.1
+)
+
 require (
 	github.com/PuerkitoBio/goquery v1.8.1 // indirect
 	github.com/andybalholm/cascadia v1.3.1 // indirect
-	github.com/bi-zone/go-fileversion v1.0.0 // indirect
 	github.com/ebitengine/gomobile v0.0.0-20240518074828-e86332849895 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.7.0 // indirect
-	github.com/google/go-github/v62 v62.0.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/gorilla/css v1.0.0 // indirect
-	github.com/hajimehoshi/ebiten/v2 v2.7.5 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
-	github.com/tinne26/etxt v0.0.8 // indirect
 	github.com/vanng822/css v1.0.1 // indirect
 	github.com/vanng822/go-premailer v1.20.2 // indirect
-	github.com/walle/targz v0.0.0-20140417120357-57fe4206da5a // indirect
 	github.com/yohamta/furex/v2 v2.4.5 // indirect
 	golang.org/x/image v0.16.0 // indirect
 	golang.org/x/net v0.7.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
-	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
-	howett.net/plist v1.0.1 // indirect
 )
@@ -0,0 +1,524 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	DEFAULT_MAX_TOTAL_SIZE = 64 << 30
+	DEFAULT_MAX_FILES      = 200000
+	// MAX_LINK_SIZE bounds how much of a zip symlink entry is read as its
+	// target.
+	MAX_LINK_SIZE = 4096
+)
+
+const (
+	FORMAT_UNKNOWN Format = iota
+	FORMAT_TAR_GZ
+	FORMAT_TAR_XZ
+	FORMAT_TAR_ZST
+	FORMAT_ZIP
+)
+
+// Format is a supported archive container and compression pair.
+type Format int
+
+var extensions = []struct {
+	ext    string
+	format Format
+}{
+	{".tar.gz", FORMAT_TAR_GZ},
+	{".tgz", FORMAT_TAR_GZ},
+	{".tar.xz", FORMAT_TAR_XZ},
+	{".txz", FORMAT_TAR_XZ},
+	{".tar.zst", FORMAT_TAR_ZST},
+	{".tzst", FORMAT_TAR_ZST},
+	{".zip", FORMAT_ZIP},
+}
+
+var magics = []struct {
+	magic  []byte
+	format Format
+}{
+	{[]byte{0x1f, 0x8b}, FORMAT_TAR_GZ},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, FORMAT_TAR_XZ},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, FORMAT_TAR_ZST},
+	{[]byte{'P', 'K', 0x03, 0x04}, FORMAT_ZIP},
+}
+
+// FormatFromName detects a format from a file name's extension.
+func FormatFromName(name string) Format {
+	name = strings.ToLower(name)
+	for _, v := range extensions {
+		if strings.HasSuffix(name, v.ext) {
+			return v.format
+		}
+	}
+
+	return FORMAT_UNKNOWN
+}
+
+// IsArchiveName reports whether name has an extension Extract understands.
+func IsArchiveName(name string) bool {
+	return FormatFromName(name) != FORMAT_UNKNOWN
+}
+
+// DetectFormat prefers the file name and falls back to the leading bytes of
+// the file for archives with an unexpected extension.
+func DetectFormat(name string, header []byte) Format {
+	if f := FormatFromName(name); f != FORMAT_UNKNOWN {
+		return f
+	}
+
+	for _, v := range magics {
+		if bytes.HasPrefix(header, v.magic) {
+			return v.format
+		}
+	}
+
+	return FORMAT_UNKNOWN
+}
+
+var (
+	ErrUnsafePath      = errors.New("archive entry escapes destination")
+	ErrUnsafeLink      = errors.New("archive link escapes destination")
+	ErrTooLarge        = errors.New("archive exceeds maximum extracted size")
+	ErrTooManyFiles    = errors.New("archive exceeds maximum file count")
+	ErrUnsupportedType = errors.New("unsupported archive format")
+)
+
+// Limits bound what a single archive may write to disk.
+type Limits struct {
+	MaxTotalSize int64
+	MaxFiles     int
+}
+
+var DefaultLimits = Limits{
+	MaxTotalSize: DEFAULT_MAX_TOTAL_SIZE,
+	MaxFiles:     DEFAULT_MAX_FILES,
+}
+
+// ProgressFunc is called with how many bytes of the archive file have been
+// consumed so far and the archive's size.
+type ProgressFunc func(done, total int64)
+
+// Extract unpacks the archive at src into dst. Entries that would land
+// outside dst, links that point outside it and writes through links are
+// rejected, and file modes are kept apart from setuid, setgid and sticky
+// bits. Every format goes through the same checks.
+func Extract(ctx context.Context, src, dst string, limits Limits, progress ProgressFunc) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	format := DetectFormat(filepath.Base(src), header[:n])
+	if format == FORMAT_ZIP {
+		return extractZip(ctx, f, info.Size(), dst, limits, progress)
+	}
+
+	r := &countingReader{ctx: ctx, r: f, total: info.Size(), progress: progress}
+
+	var tr io.Reader
+	switch format {
+	case FORMAT_TAR_GZ:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		tr = gz
+	case FORMAT_TAR_XZ:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return err
+		}
+		tr = xr
+	case FORMAT_TAR_ZST:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		tr = zr
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, filepath.Base(src))
+	}
+
+	return extractTar(ctx, tar.NewReader(tr), dst, limits)
+}
+
+func extractTar(ctx context.Context, tr *tar.Reader, dst string, limits Limits) error {
+	x := &extractor{dst: dst, limits: limits}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		mode := fs.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(hdr.Name, mode)
+		case tar.TypeReg, tar.TypeRegA:
+			err = x.file(hdr.Name, mode, tr)
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(hdr.Name, hdr.Linkname)
+		default:
+			// Devices, fifos and the like have no place in a game build.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(ctx context.Context, f *os.File, size int64, dst string, limits Limits, progress ProgressFunc) error {
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return err
+	}
+
+	x := &extractor{dst: dst, limits: limits}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	var done int64
+	for _, zf := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := x.zipEntry(zf); err != nil {
+			return err
+		}
+
+		done += int64(zf.CompressedSize64)
+		if progress != nil {
+			progress(min(done, size), size)
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) zipEntry(zf *zip.File) error {
+	mode := zf.Mode()
+
+	// Zips written on Windows carry no Unix permissions, so give their
+	// files the executable bit rather than leave binaries unrunnable.
+	if !hasUnixMode(zf) && !mode.IsDir() {
+		mode = 0755
+	}
+
+	switch {
+	case mode.IsDir():
+		return x.dir(zf.Name, mode.Perm())
+	case mode&fs.ModeSymlink != 0:
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		link, err := io.ReadAll(io.LimitReader(rc, MAX_LINK_SIZE))
+		if err != nil {
+			return err
+		}
+
+		return x.symlink(zf.Name, string(link))
+	case mode.IsRegular():
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		return x.file(zf.Name, mode.Perm(), rc)
+	default:
+		return nil
+	}
+}
+
+// hasUnixMode reports whether the zip entry was written by a Unix or macOS
+// tool that stores permissions in the external attributes.
+func hasUnixMode(zf *zip.File) bool {
+	switch zf.CreatorVersion >> 8 {
+	case 3, 19:
+		return true
+	default:
+		return false
+	}
+}
+
+// extractor writes entries beneath dst while enforcing limits.
+type extractor struct {
+	dst     string
+	limits  Limits
+	written int64
+	files   int
+}
+
+// target resolves an entry name to a path inside dst. Backslashes are
+// separators, as Windows tools write them, so ..\ climbs on every platform.
+func (x *extractor) target(name string) (string, error) {
+	rel := filepath.FromSlash(path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, `\`, "/"), "./")))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	if err := x.checkParents(rel); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(x.dst, rel), nil
+}
+
+// checkParents refuses to write through a symlink that is already on disk,
+// even one that was validated when it was created.
+func (x *extractor) checkParents(rel string) error {
+	p := x.dst
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for _, part := range parts {
+		if part == "." || part == "" {
+			continue
+		}
+		p = filepath.Join(p, part)
+
+		info, err := os.Lstat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is a link", ErrUnsafeLink, rel)
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) count() error {
+	x.files++
+	if x.limits.MaxFiles > 0 && x.files > x.limits.MaxFiles {
+		return ErrTooManyFiles
+	}
+
+	return nil
+}
+
+func (x *extractor) dir(name string, mode fs.FileMode) error {
+	p, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if err = x.count(); err != nil {
+		return err
+	}
+
+	return os.MkdirAll(p, mode|0700)
+}
+
+func (x *extractor) file(name string, mode fs.FileMode, r io.Reader) error {
+	p, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if err = x.count(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// Replace rather than follow anything already at p.
+	if err = os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	out, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode|0600)
+	if err != nil {
+		return err
+	}
+
+	remaining := int64(-1)
+	if x.limits.MaxTotalSize > 0 {
+		remaining = x.limits.MaxTotalSize - x.written
+	}
+
+	src := r
+	if remaining >= 0 {
+		// Read one byte past the budget so an overrun is detectable.
+		src = io.LimitReader(r, remaining+1)
+	}
+
+	n, err := io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	x.written += n
+	if err != nil {
+		return err
+	}
+
+	if remaining >= 0 && n > remaining {
+		return ErrTooLarge
+	}
+
+	// OpenFile is subject to the umask, so set the mode explicitly.
+	return os.Chmod(p, mode|0600)
+}
+
+func (x *extractor) symlink(name, link string) error {
+	p, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if err = x.count(); err != nil {
+		return err
+	}
+
+	rel, _ := filepath.Rel(x.dst, p)
+	if filepath.IsAbs(link) || climbsAfterName(link) || !filepath.IsLocal(filepath.Join(filepath.Dir(rel), filepath.FromSlash(link))) {
+		return fmt.Errorf("%w: %s -> %s", ErrUnsafeLink, name, link)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return os.Symlink(filepath.FromSlash(link), p)
+}
+
+// climbsAfterName reports whether a link target has a ".." after a named
+// element. The lexical check in symlink cannot see that the named element
+// may be a link, now or after a later entry, so s1 -> . followed by
+// s2 -> s1/.. would resolve outside dst. Only leading ".." elements climb
+// through directories that are known to be real.
+func climbsAfterName(link string) bool {
+	named := false
+	for _, part := range strings.FieldsFunc(filepath.ToSlash(link), func(r rune) bool { return r == '/' }) {
+		switch part {
+		case ".":
+		case "..":
+			if named {
+				return true
+			}
+		default:
+			named = true
+		}
+	}
+
+	return false
+}
+
+func (x *extractor) hardlink(name, link string) error {
+	p, err := x.target(name)
+	if err != nil {
+		return err
+	}
+
+	src, err := x.target(link)
+	if err != nil {
+		return fmt.Errorf("%w: %s => %s", ErrUnsafeLink, name, link)
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s => %s", ErrUnsafeLink, name, link)
+	}
+
+	if err = x.count(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return os.Link(src, p)
+}
+
+// countingReader reports how far through the archive file extraction is and
+// stops reading once ctx is cancelled.
+type countingReader struct {
+	ctx      context.Context
+	r        io.Reader
+	done     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := c.r.Read(b)
+	c.done += int64(n)
+	if c.progress != nil {
+		c.progress(c.done, c.total)
+	}
+
+	return n, err
+}
@@ -0,0 +1,499 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+type tarEntry struct {
+	name string
+	link string
+	body string
+	// hard makes an entry with a link a hardlink rather than a symlink.
+	hard bool
+	// mode defaults to 0644.
+	mode int64
+}
+
+// writeArchive writes entries to an archive called name in a temp dir, in
+// the format its extension names. Entries with a link are symlinks unless
+// they are hard, which only tar supports.
+func writeArchive(t *testing.T, name string, entries []tarEntry) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	switch FormatFromName(name) {
+	case FORMAT_TAR_GZ:
+		gz := gzip.NewWriter(f)
+		writeTar(t, gz, entries)
+		err = gz.Close()
+	case FORMAT_TAR_XZ:
+		xw, err := xz.NewWriter(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTar(t, xw, entries)
+		err = xw.Close()
+	case FORMAT_TAR_ZST:
+		zw, err := zstd.NewWriter(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTar(t, zw, entries)
+		err = zw.Close()
+	case FORMAT_ZIP:
+		writeZip(t, f, entries)
+	default:
+		t.Fatalf("no format for %s", name)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func entryMode(e tarEntry) int64 {
+	if e.mode == 0 {
+		return 0644
+	}
+
+	return e.mode
+}
+
+func writeTar(t *testing.T, w io.Writer, entries []tarEntry) {
+	t.Helper()
+
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: entryMode(e), Typeflag: tar.TypeReg, Size: int64(len(e.body))}
+		switch {
+		case e.link != "" && e.hard:
+			hdr = &tar.Header{Name: e.name, Mode: entryMode(e), Typeflag: tar.TypeLink, Linkname: e.link}
+		case e.link != "":
+			hdr = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.link}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeZip stores modes as a Unix tool would. Symlinks keep their target as
+// the entry's contents.
+func writeZip(t *testing.T, w io.Writer, entries []tarEntry) {
+	t.Helper()
+
+	zw := zip.NewWriter(w)
+	for _, e := range entries {
+		if e.hard {
+			t.Fatalf("zip has no hardlinks: %s", e.name)
+		}
+
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		if e.link != "" {
+			hdr.SetMode(fs.ModeSymlink | 0777)
+			body = e.link
+		} else {
+			hdr.SetMode(fs.FileMode(entryMode(e)).Perm())
+		}
+
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractSymlinkChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name: "climb through earlier link",
+			entries: []tarEntry{
+				{name: "s1", link: "."},
+				{name: "s2", link: "s1/.."},
+			},
+		},
+		{
+			name: "climb through later link",
+			entries: []tarEntry{
+				{name: "s2", link: "s1/.."},
+				{name: "s1", link: "."},
+			},
+		},
+		{
+			name: "nested climb",
+			entries: []tarEntry{
+				{name: "a/s1", link: ".."},
+				{name: "s2", link: "a/s1/../.."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "game")
+
+			err := Extract(context.Background(), writeArchive(t, "game.tar.gz", tt.entries), dst, DefaultLimits, nil)
+			if !errors.Is(err, ErrUnsafeLink) {
+				t.Fatalf("Extract = %v, want %v", err, ErrUnsafeLink)
+			}
+			if _, err = os.Lstat(filepath.Join(dst, "s2")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("escaping link was created: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractSymlinks(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "game")
+	entries := []tarEntry{
+		{name: "lib/libgame.so.1", body: "elf"},
+		{name: "lib/libgame.so", link: "libgame.so.1"},
+		{name: "bin/libgame.so", link: "../lib/libgame.so"},
+		{name: "current", link: "./lib"},
+	}
+
+	if err := Extract(context.Background(), writeArchive(t, "game.tar.gz", entries), dst, DefaultLimits, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"lib/libgame.so", "bin/libgame.so", "current/libgame.so.1"} {
+		b, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "elf" {
+			t.Errorf("%s = %q, want %q", name, b, "elf")
+		}
+	}
+}
+
+// escaped reports any file named evil beneath root, which the unsafe
+// archives below try to write next to or above dst.
+func escaped(t *testing.T, root string) []string {
+	t.Helper()
+
+	var found []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(d.Name(), "evil") {
+			found = append(found, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return found
+}
+
+func hasHardlink(entries []tarEntry) bool {
+	for _, e := range entries {
+		if e.hard {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestExtractUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    error
+	}{
+		{"parent", []tarEntry{{name: "../evil", body: "x"}}, ErrUnsafePath},
+		{"nested parent", []tarEntry{{name: "bin/../../evil", body: "x"}}, ErrUnsafePath},
+		{"absolute", []tarEntry{{name: "/tmp/evil", body: "x"}}, ErrUnsafePath},
+		{"windows parent", []tarEntry{{name: `..\evil`, body: "x"}}, ErrUnsafePath},
+		{"windows nested parent", []tarEntry{{name: `bin\..\..\evil`, body: "x"}}, ErrUnsafePath},
+		{"symlink parent", []tarEntry{{name: "evil", link: "../evil"}}, ErrUnsafeLink},
+		{"symlink nested parent", []tarEntry{{name: "bin/evil", link: "../../evil"}}, ErrUnsafeLink},
+		{"symlink absolute", []tarEntry{{name: "evil", link: "/etc/passwd"}}, ErrUnsafeLink},
+		{"symlink write through", []tarEntry{{name: "lib", link: "."}, {name: "lib/evil", body: "x"}}, ErrUnsafeLink},
+		{"hardlink parent", []tarEntry{{name: "evil", link: "../outside", hard: true}}, ErrUnsafeLink},
+		{"hardlink absolute", []tarEntry{{name: "evil", link: "/etc/passwd", hard: true}}, ErrUnsafeLink},
+		{"hardlink to symlink", []tarEntry{{name: "lib", link: "."}, {name: "evil", link: "lib", hard: true}}, ErrUnsafeLink},
+		{"hardlink to missing", []tarEntry{{name: "evil", link: "missing", hard: true}}, fs.ErrNotExist},
+	}
+
+	// Zip entries go through their own reader, so check both.
+	for _, format := range []string{"game.tar.gz", "game.zip"} {
+		for _, tt := range tests {
+			t.Run(format+"/"+tt.name, func(t *testing.T) {
+				if format == "game.zip" && hasHardlink(tt.entries) {
+					t.Skip("zip has no hardlinks")
+				}
+
+				root := t.TempDir()
+				dst := filepath.Join(root, "library", "game")
+				// A file the hardlink cases try to reach.
+				if err := os.MkdirAll(filepath.Join(root, "library"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(root, "library", "outside"), []byte("secret"), 0644); err != nil {
+					t.Fatal(err)
+				}
+
+				err := Extract(context.Background(), writeArchive(t, format, tt.entries), dst, DefaultLimits, nil)
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Extract = %v, want %v", err, tt.want)
+				}
+				if found := escaped(t, root); len(found) > 0 {
+					t.Errorf("unsafe entries were written: %v", found)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	entries := []tarEntry{
+		{name: "a", body: "123456"},
+		{name: "b", body: "123456"},
+		{name: "c/d", link: "../a"},
+	}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		want   error
+	}{
+		{"within limits", Limits{MaxTotalSize: 12, MaxFiles: 3}, nil},
+		{"unlimited", Limits{}, nil},
+		{"too many files", Limits{MaxFiles: 2}, ErrTooManyFiles},
+		{"too large", Limits{MaxTotalSize: 11}, ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "game")
+
+			err := Extract(context.Background(), writeArchive(t, "game.tar.gz", entries), dst, tt.limits, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Extract = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no permission bits")
+	}
+
+	tests := []struct {
+		name string
+		mode int64
+		want fs.FileMode
+	}{
+		{"executable", 0755, 0755},
+		{"read only", 0444, 0644},
+		{"setuid", 04755, 0755},
+		{"setgid", 02755, 0755},
+		{"sticky", 01777, 0777},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "game")
+			entries := []tarEntry{{name: "bin/game", body: "elf", mode: tt.mode}}
+
+			if err := Extract(context.Background(), writeArchive(t, "game.tar.gz", entries), dst, DefaultLimits, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(filepath.Join(dst, "bin", "game"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode(); got != tt.want {
+				t.Errorf("mode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFormats(t *testing.T) {
+	entries := []tarEntry{
+		{name: "bin/game", body: "elf", mode: 0755},
+		{name: "data/levels/1.dat", body: "level one"},
+		{name: "lib/libgame.so.1", body: "lib"},
+		{name: "lib/libgame.so", link: "libgame.so.1"},
+	}
+	want := map[string]string{
+		"bin/game":          "elf",
+		"data/levels/1.dat": "level one",
+		"lib/libgame.so":    "lib",
+	}
+
+	for _, name := range []string{"game.tar.gz", "game.tar.xz", "game.tar.zst", "game.zip"} {
+		t.Run(name, func(t *testing.T) {
+			src := writeArchive(t, name, entries)
+
+			// The same archive without its extension is found by its magic.
+			b, err := os.ReadFile(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			renamed := filepath.Join(filepath.Dir(src), "game.bin")
+			if err = os.WriteFile(renamed, b, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, src := range []string{src, renamed} {
+				dst := filepath.Join(t.TempDir(), "game")
+				if err := Extract(context.Background(), src, dst, DefaultLimits, nil); err != nil {
+					t.Fatal(err)
+				}
+
+				for name, body := range want {
+					b, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(b) != body {
+						t.Errorf("%s = %q, want %q", name, b, body)
+					}
+				}
+
+				info, err := os.Stat(filepath.Join(dst, "bin", "game"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+					t.Errorf("bin/game mode = %v, want %v", info.Mode().Perm(), fs.FileMode(0755))
+				}
+			}
+		})
+	}
+}
+
+func TestExtractZipWithoutModes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Create leaves the creator as MS-DOS, as Windows tools write it.
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("Game.exe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("MZ")); err != nil {
+		t.Fatal(err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "game")
+	if err = Extract(context.Background(), path, dst, DefaultLimits, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "Game.exe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), fs.FileMode(0755))
+	}
+}
+
+func TestFormatFromName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Format
+	}{
+		{"keizai-linux-amd64.tar.gz", FORMAT_TAR_GZ},
+		{"keizai-linux-amd64.tgz", FORMAT_TAR_GZ},
+		{"keizai-linux-amd64.tar.xz", FORMAT_TAR_XZ},
+		{"keizai-linux-amd64.txz", FORMAT_TAR_XZ},
+		{"keizai-linux-amd64.tar.zst", FORMAT_TAR_ZST},
+		{"keizai-linux-amd64.tzst", FORMAT_TAR_ZST},
+		{"keizai-windows-amd64.zip", FORMAT_ZIP},
+		{"Keizai-Windows-AMD64.ZIP", FORMAT_ZIP},
+		{"keizai-linux-amd64.tar", FORMAT_UNKNOWN},
+		{"keizai-linux-amd64.gz", FORMAT_UNKNOWN},
+		{"keizai-linux-amd64.tar.gz.sha256", FORMAT_UNKNOWN},
+		{"SHA256SUMS", FORMAT_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFromName(tt.name); got != tt.want {
+			t.Errorf("FormatFromName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := IsArchiveName(tt.name); got != (tt.want != FORMAT_UNKNOWN) {
+			t.Errorf("IsArchiveName(%q) = %v", tt.name, got)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Format
+	}{
+		{"game.bin", []byte{0x1f, 0x8b, 0x08, 0x00}, FORMAT_TAR_GZ},
+		{"game.bin", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00, 0x04}, FORMAT_TAR_XZ},
+		{"game.bin", []byte{0x28, 0xb5, 0x2f, 0xfd, 0x04}, FORMAT_TAR_ZST},
+		{"game.bin", []byte{'P', 'K', 0x03, 0x04}, FORMAT_ZIP},
+		{"game.bin", []byte("#!/bin/sh\n"), FORMAT_UNKNOWN},
+		{"game.bin", nil, FORMAT_UNKNOWN},
+		// A truncated magic is not a match.
+		{"game.bin", []byte{0xfd, '7', 'z'}, FORMAT_UNKNOWN},
+		// The name wins over the contents.
+		{"game.zip", []byte{0x1f, 0x8b}, FORMAT_ZIP},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.name, tt.header); got != tt.want {
+			t.Errorf("DetectFormat(%q, % x) = %v, want %v", tt.name, tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const (
+	CONFIG_DIR_NAME  = "engehost"
+	CONFIG_FILE_NAME = "config.json"
+)
+
+const (
+	DEFAULT_KEEP_VERSIONS = 2
+)
+
+var (
+	ErrRelativeLibrary = errors.New("library folder must be an absolute path")
+)
+
+const (
+	CHANNEL_STABLE Channel = "stable"
+	CHANNEL_BETA   Channel = "beta"
+	CHANNEL_PINNED Channel = "pinned"
+)
+
+// Channel selects which releases of a game the launcher installs.
+type Channel string
+
+type GameConfig struct {
+	Channel   Channel `json:"channel"`
+	PinnedTag string  `json:"pinned_tag,omitempty"`
+	// Library is the folder the game is installed into next. Empty means
+	// the platform's default library.
+	Library string `json:"library,omitempty"`
+	// LaunchArgs are passed after the registry's arguments, LaunchEnv
+	// overrides the registry's variables and LaunchDir replaces its working
+	// directory. A relative LaunchDir is taken from the installed bundle.
+	LaunchArgs []string          `json:"launch_args,omitempty"`
+	LaunchEnv  map[string]string `json:"launch_env,omitempty"`
+	LaunchDir  string            `json:"launch_dir,omitempty"`
+}
+
+// Config is the launcher's persisted settings. It is safe for concurrent use.
+type Config struct {
+	mu   sync.Mutex
+	path string
+
+	Games map[int]GameConfig `json:"games"`
+	// RegistryKeys and PublisherKeys hold base64 Ed25519 public keys that are
+	// trusted in addition to the ones shipped with the launcher. Publisher
+	// keys are grouped by repo owner.
+	RegistryKeys  []string            `json:"registry_keys,omitempty"`
+	PublisherKeys map[string][]string `json:"publisher_keys,omitempty"`
+	// MaxConcurrentDownloads limits how many install and update jobs run at
+	// once. Zero uses the download manager's default.
+	MaxConcurrentDownloads int `json:"max_concurrent_downloads,omitempty"`
+	// Libraries are folders games can be installed into in addition to the
+	// platform's default library.
+	Libraries []string `json:"libraries,omitempty"`
+	// KeepVersions is how many previous versions of each game are kept for
+	// rollback. Zero uses DEFAULT_KEEP_VERSIONS and a negative value keeps
+	// none. MaxKeptVersionsSize caps the bytes those versions may take per
+	// game; zero means no cap.
+	KeepVersions        int   `json:"keep_versions,omitempty"`
+	MaxKeptVersionsSize int64 `json:"max_kept_versions_size,omitempty"`
+}
+
+// Dir is where the launcher keeps its config and other small state files.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, CONFIG_DIR_NAME), nil
+}
+
+// DataDir is where the launcher keeps bulkier data, like game logs. Linux
+// separates it from config under XDG_DATA_HOME; elsewhere it is Dir.
+func DataDir() (string, error) {
+	if runtime.GOOS != "linux" {
+		return Dir()
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, CONFIG_DIR_NAME), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", CONFIG_DIR_NAME), nil
+}
+
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, CONFIG_FILE_NAME), nil
+}
+
+// Load reads the config at path. A missing file yields an empty config that
+// will be created on the first Save.
+func Load(path string) (*Config, error) {
+	c := &Config{
+		path:  path,
+		Games: make(map[int]GameConfig),
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err = json.NewDecoder(f).Decode(c); err != nil {
+		return nil, err
+	}
+
+	if c.Games == nil {
+		c.Games = make(map[int]GameConfig)
+	}
+
+	return c, nil
+}
+
+func (c *Config) Game(id int) GameConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	gc := c.Games[id]
+	if gc.Channel == "" {
+		gc.Channel = CHANNEL_STABLE
+	}
+
+	// Callers may change what they get back without racing other readers.
+	gc.LaunchArgs = append([]string(nil), gc.LaunchArgs...)
+	gc.LaunchEnv = maps.Clone(gc.LaunchEnv)
+
+	return gc
+}
+
+func (c *Config) GetRegistryKeys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.RegistryKeys...)
+}
+
+func (c *Config) GetPublisherKeys(repoOwner string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.PublisherKeys[repoOwner]...)
+}
+
+func (c *Config) GetMaxConcurrentDownloads() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.MaxConcurrentDownloads
+}
+
+func (c *Config) GetLibraries() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.Libraries...)
+}
+
+// AddLibrary adds a library folder. Adding one that is already configured
+// does nothing.
+func (c *Config) AddLibrary(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%w: %s", ErrRelativeLibrary, path)
+	}
+	path = filepath.Clean(path)
+
+	c.mu.Lock()
+	for _, l := range c.Libraries {
+		if l == path {
+			c.mu.Unlock()
+			return nil
+		}
+	}
+	c.Libraries = append(c.Libraries, path)
+	c.mu.Unlock()
+
+	return c.Save()
+}
+
+// RemoveLibrary forgets a library folder. Games already installed there
+// stay where they are.
+func (c *Config) RemoveLibrary(path string) error {
+	path = filepath.Clean(path)
+
+	c.mu.Lock()
+	for i, l := range c.Libraries {
+		if l == path {
+			c.Libraries = append(c.Libraries[:i], c.Libraries[i+1:]...)
+			break
+		}
+	}
+	for id, gc := range c.Games {
+		if gc.Library == path {
+			gc.Library = ""
+			c.Games[id] = gc
+		}
+	}
+	c.mu.Unlock()
+
+	return c.Save()
+}
+
+func (c *Config) GetKeepVersions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.KeepVersions < 0:
+		return 0
+	case c.KeepVersions == 0:
+		return DEFAULT_KEEP_VERSIONS
+	default:
+		return c.KeepVersions
+	}
+}
+
+func (c *Config) GetMaxKeptVersionsSize() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.MaxKeptVersionsSize
+}
+
+func (c *Config) SetGame(id int, gc GameConfig) error {
+	c.mu.Lock()
+	c.Games[id] = gc
+	c.mu.Unlock()
+
+	return c.Save()
+}
+
+// Save writes the config to a temporary file and renames it into place so a
+// crash never leaves a truncated config behind.
+func (c *Config) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), CONFIG_FILE_NAME+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(c); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path)
+}
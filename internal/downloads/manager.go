@@ -0,0 +1,486 @@
+package downloads
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	JOB_INSTALL JobKind = "install"
+	JOB_UPDATE  JobKind = "update"
+	// JOB_REINSTALL installs the release named by the job's Tag instead of
+	// the one the game's channel selects.
+	JOB_REINSTALL JobKind = "reinstall"
+	// JOB_REPAIR downloads the installed release again to restore damaged
+	// files.
+	JOB_REPAIR JobKind = "repair"
+	// JOB_MOVE moves an installed game into the job's Library.
+	JOB_MOVE JobKind = "move"
+)
+
+const (
+	JOB_QUEUED    JobState = "queued"
+	JOB_ACTIVE    JobState = "active"
+	JOB_PAUSED    JobState = "paused"
+	JOB_DONE      JobState = "done"
+	JOB_FAILED    JobState = "failed"
+	JOB_CANCELLED JobState = "cancelled"
+)
+
+const (
+	DEFAULT_CONCURRENCY = 2
+	QUEUE_FILE_NAME     = "downloads.json"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+)
+
+type JobKind string
+
+type JobState string
+
+func (s JobState) Finished() bool {
+	return s == JOB_DONE || s == JOB_FAILED || s == JOB_CANCELLED
+}
+
+type Progress struct {
+	Fraction float64
+	Text     string
+}
+
+type Job struct {
+	ID         string    `json:"id"`
+	GameID     int       `json:"game_id"`
+	GameName   string    `json:"game_name"`
+	Kind       JobKind   `json:"kind"`
+	Tag        string    `json:"tag,omitempty"`
+	Library    string    `json:"library,omitempty"`
+	Priority   int       `json:"priority"`
+	State      JobState  `json:"state"`
+	Err        string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Progress   Progress  `json:"-"`
+}
+
+// jobRun is one invocation of the Runner for a job.
+type jobRun struct {
+	cancel context.CancelFunc
+}
+
+// Runner does the work for a job. It must return once ctx is cancelled, which
+// happens when the job is paused or cancelled or the manager shuts down.
+type Runner func(ctx context.Context, job Job, report func(Progress)) error
+
+// Manager runs install and update jobs from a persisted queue, at most limit
+// at a time, highest priority first.
+type Manager struct {
+	mu       sync.Mutex
+	path     string
+	limit    int
+	run      Runner
+	onChange func(Job)
+	notify   []Job
+	jobs     []*Job
+	// runs holds the runner of each job that has one, including runners
+	// that were cancelled but have not returned yet.
+	runs   map[string]*jobRun
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager loads the queue saved at path. Jobs that were running when the
+// launcher last exited are queued again.
+func NewManager(path string, limit int, run Runner) (*Manager, error) {
+	if limit <= 0 {
+		limit = DEFAULT_CONCURRENCY
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := &Manager{
+		path:   path,
+		limit:  limit,
+		run:    run,
+		runs:   make(map[string]*jobRun),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err = json.NewDecoder(f).Decode(&m.jobs); err != nil {
+		return nil, err
+	}
+
+	for _, j := range m.jobs {
+		if j.State == JOB_ACTIVE {
+			j.State = JOB_QUEUED
+		}
+	}
+
+	return m, nil
+}
+
+// SetOnChange registers f to be called, from the goroutine that made the
+// change, whenever a job changes state or reports progress.
+func (m *Manager) SetOnChange(f func(Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onChange = f
+}
+
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.unlock()
+
+	m.schedule()
+}
+
+// Enqueue adds a job for a game. If the game already has an unfinished job
+// that job is returned instead.
+func (m *Manager) Enqueue(gameID int, gameName string, kind JobKind, priority int) (Job, error) {
+	return m.enqueue(Job{GameID: gameID, GameName: gameName, Kind: kind, Priority: priority})
+}
+
+// EnqueueRelease adds a job that installs a specific release of a game.
+func (m *Manager) EnqueueRelease(gameID int, gameName, tag string, priority int) (Job, error) {
+	return m.enqueue(Job{GameID: gameID, GameName: gameName, Kind: JOB_REINSTALL, Tag: tag, Priority: priority})
+}
+
+// EnqueueMove adds a job that moves an installed game into library.
+func (m *Manager) EnqueueMove(gameID int, gameName, library string, priority int) (Job, error) {
+	return m.enqueue(Job{GameID: gameID, GameName: gameName, Kind: JOB_MOVE, Library: library, Priority: priority})
+}
+
+func (m *Manager) enqueue(job Job) (Job, error) {
+	m.mu.Lock()
+	defer m.unlock()
+
+	for _, j := range m.jobs {
+		if j.GameID == job.GameID && !j.State.Finished() {
+			return *j, nil
+		}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	j := &job
+	j.ID = id
+	j.State = JOB_QUEUED
+	j.CreatedAt = time.Now()
+	m.jobs = append(m.jobs, j)
+
+	m.changed(j)
+	m.schedule()
+
+	return *j, m.save()
+}
+
+func (m *Manager) Pause(id string) error {
+	return m.transition(id, func(j *Job) error {
+		switch j.State {
+		case JOB_ACTIVE:
+			m.runs[j.ID].cancel()
+		case JOB_QUEUED:
+		default:
+			return fmt.Errorf("cannot pause %s job", j.State)
+		}
+		j.State = JOB_PAUSED
+		return nil
+	})
+}
+
+func (m *Manager) Resume(id string) error {
+	return m.transition(id, func(j *Job) error {
+		switch j.State {
+		case JOB_PAUSED, JOB_FAILED:
+		default:
+			return fmt.Errorf("cannot resume %s job", j.State)
+		}
+		j.State = JOB_QUEUED
+		j.Err = ""
+		return nil
+	})
+}
+
+func (m *Manager) Cancel(id string) error {
+	return m.transition(id, func(j *Job) error {
+		if j.State.Finished() {
+			return fmt.Errorf("cannot cancel %s job", j.State)
+		}
+		if j.State == JOB_ACTIVE {
+			m.runs[j.ID].cancel()
+		}
+		j.State = JOB_CANCELLED
+		j.FinishedAt = time.Now()
+		return nil
+	})
+}
+
+func (m *Manager) SetPriority(id string, priority int) error {
+	return m.transition(id, func(j *Job) error {
+		j.Priority = priority
+		return nil
+	})
+}
+
+// Remove drops a finished job from the list.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, j := range m.jobs {
+		if j.ID != id {
+			continue
+		}
+		if !j.State.Finished() {
+			return fmt.Errorf("cannot remove %s job", j.State)
+		}
+		m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+		return m.save()
+	}
+
+	return ErrJobNotFound
+}
+
+// Jobs returns a snapshot of every job, active first, then queued by
+// priority, then paused, failed, done and cancelled.
+func (m *Manager) Jobs() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, *j)
+	}
+
+	sort.SliceStable(jobs, func(a, b int) bool {
+		if stateOrder(jobs[a].State) != stateOrder(jobs[b].State) {
+			return stateOrder(jobs[a].State) < stateOrder(jobs[b].State)
+		}
+		return jobs[a].Priority > jobs[b].Priority
+	})
+
+	return jobs
+}
+
+// Pending returns the unfinished job for a game, if there is one.
+func (m *Manager) Pending(gameID int) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, j := range m.jobs {
+		if j.GameID == gameID && !j.State.Finished() {
+			return *j, true
+		}
+	}
+
+	return Job{}, false
+}
+
+// Close stops running jobs so they resume on the next start and saves the
+// queue.
+func (m *Manager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.save()
+}
+
+func (m *Manager) transition(id string, f func(j *Job) error) error {
+	m.mu.Lock()
+	defer m.unlock()
+
+	for _, j := range m.jobs {
+		if j.ID != id {
+			continue
+		}
+		if err := f(j); err != nil {
+			return err
+		}
+
+		m.changed(j)
+		m.schedule()
+
+		return m.save()
+	}
+
+	return ErrJobNotFound
+}
+
+// schedule starts queued jobs while there is capacity. m.mu must be held.
+func (m *Manager) schedule() {
+	if m.ctx.Err() != nil {
+		return
+	}
+
+	// Runners still winding down count against the limit, and a job
+	// resumed before its last runner returned waits for it, so two runners
+	// never work on the same job.
+	for len(m.runs) < m.limit {
+		var next *Job
+		for _, j := range m.jobs {
+			if _, busy := m.runs[j.ID]; busy || j.State != JOB_QUEUED {
+				continue
+			}
+			if next == nil || j.Priority > next.Priority || (j.Priority == next.Priority && j.CreatedAt.Before(next.CreatedAt)) {
+				next = j
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		m.start(next)
+	}
+}
+
+// start runs j in the background. m.mu must be held.
+func (m *Manager) start(j *Job) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	r := &jobRun{cancel: cancel}
+	m.runs[j.ID] = r
+
+	j.State = JOB_ACTIVE
+	j.Err = ""
+	j.Progress = Progress{}
+	m.changed(j)
+
+	job := *j
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		err := m.run(ctx, job, func(p Progress) {
+			m.mu.Lock()
+			defer m.unlock()
+
+			if j.State == JOB_ACTIVE {
+				j.Progress = p
+				m.changed(j)
+			}
+		})
+
+		m.mu.Lock()
+		defer m.unlock()
+
+		cancel()
+		if m.runs[j.ID] == r {
+			delete(m.runs, j.ID)
+		}
+
+		// Pause and Cancel have already moved the job on; a manager shutdown
+		// leaves it active so it is queued again on the next start.
+		if j.State == JOB_ACTIVE && m.ctx.Err() == nil {
+			j.FinishedAt = time.Now()
+			if err != nil {
+				j.State = JOB_FAILED
+				j.Err = err.Error()
+			} else {
+				j.State = JOB_DONE
+				j.Progress = Progress{Fraction: 1}
+			}
+			m.changed(j)
+		}
+
+		m.save()
+		m.schedule()
+	}()
+}
+
+// changed queues a notification for the listener. m.mu must be held.
+func (m *Manager) changed(j *Job) {
+	if m.onChange != nil {
+		m.notify = append(m.notify, *j)
+	}
+}
+
+// unlock releases m.mu and then delivers queued notifications, so listeners
+// are free to call back into the manager.
+func (m *Manager) unlock() {
+	notify, onChange := m.notify, m.onChange
+	m.notify = nil
+	m.mu.Unlock()
+
+	for _, j := range notify {
+		onChange(j)
+	}
+}
+
+// save persists the queue. m.mu must be held.
+func (m *Manager) save() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), QUEUE_FILE_NAME+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = json.NewEncoder(tmp).Encode(m.jobs); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), m.path)
+}
+
+func stateOrder(s JobState) int {
+	switch s {
+	case JOB_ACTIVE:
+		return 0
+	case JOB_QUEUED:
+		return 1
+	case JOB_PAUSED:
+		return 2
+	case JOB_FAILED:
+		return 3
+	case JOB_DONE:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,98 @@
+package downloads
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, f func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !f() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPauseResumePause(t *testing.T) {
+	var runs, active, maxActive atomic.Int32
+	// release lets a cancelled runner return, so the test controls how long
+	// it outlives its pause.
+	release := make(chan struct{})
+
+	// With room for two runners a resumed job could get a second runner
+	// while its first is still winding down.
+	m, err := NewManager(filepath.Join(t.TempDir(), QUEUE_FILE_NAME), 2, func(ctx context.Context, job Job, report func(Progress)) error {
+		runs.Add(1)
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			cur := maxActive.Load()
+			if n <= cur || maxActive.CompareAndSwap(cur, n) {
+				break
+			}
+		}
+
+		<-ctx.Done()
+		<-release
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		close(release)
+		m.Close()
+	}()
+	m.Start()
+
+	job, err := m.Enqueue(1, "Keizai", JOB_INSTALL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "first run", func() bool { return runs.Load() == 1 })
+
+	if err = m.Pause(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Resume(job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The paused runner has not returned, so the job must wait for it.
+	time.Sleep(20 * time.Millisecond)
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("%d runs started while the paused one was still running", n)
+	}
+	if j, _ := m.Pending(1); j.State != JOB_QUEUED {
+		t.Fatalf("resumed job is %s, want %s", j.State, JOB_QUEUED)
+	}
+
+	// The old runner returning starts the resumed job.
+	release <- struct{}{}
+	waitFor(t, "second run", func() bool {
+		j, _ := m.Pending(1)
+		return runs.Load() == 2 && j.State == JOB_ACTIVE
+	})
+
+	// Pausing again cancels the second runner, which the first one must
+	// not have forgotten.
+	if err = m.Pause(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	release <- struct{}{}
+	waitFor(t, "second run to return", func() bool { return active.Load() == 0 })
+
+	if j, _ := m.Pending(1); j.State != JOB_PAUSED {
+		t.Errorf("job is %s, want %s", j.State, JOB_PAUSED)
+	}
+	if n := maxActive.Load(); n > 1 {
+		t.Errorf("%d runners worked on the job at once", n)
+	}
+}
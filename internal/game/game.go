@@ -36,6 +36,7 @@ type Game struct {
 	txtRender   *etxt.Renderer
 	drawables   []Drawable
 	sharedState *StateStore
+	tasks       *TaskRunner
 }
 
 type Drawable interface {
@@ -43,13 +44,18 @@ type Drawable interface {
 	Update(*Game) error
 }
 
-func NewGame(r *etxt.Renderer, d []Drawable, ss *StateStore) *Game {
+func NewGame(r *etxt.Renderer, d []Drawable, ss *StateStore, tasks *TaskRunner) *Game {
 	return &Game{
 		txtRender:   r,
 		drawables:   d,
 		sharedState: ss,
+		tasks:       tasks,
 	}
 }
+
+func (g *Game) Tasks() *TaskRunner {
+	return g.tasks
+}
 func (g *Game) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{42, 42, 42, 0})
 	for _, v := range g.drawables {
@@ -58,6 +64,10 @@ func (g *Game) Draw(screen *ebiten.Image) {
 }
 
 func (g *Game) Update() error {
+	if err := g.tasks.Update(); err != nil {
+		return err
+	}
+
 	for _, v := range g.drawables {
 		if err := v.Update(g); err != nil {
 			return err
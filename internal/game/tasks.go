@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	TASK_RESULT_BUFFER_SIZE = 64
+)
+
+// TaskFunc runs off the update goroutine. It should return promptly once ctx
+// is cancelled.
+type TaskFunc func(ctx context.Context) (interface{}, error)
+
+// TaskDone receives a task's outcome on the update goroutine, where it is
+// safe to change widget state. Returning an error stops the game like any
+// other Update error.
+type TaskDone func(result interface{}, err error) error
+
+type Task struct {
+	cancel context.CancelFunc
+}
+
+func (t *Task) Cancel() {
+	t.cancel()
+}
+
+// TaskRunner runs long work in the background and hands results back to the
+// game loop, which drains them at the start of every Update.
+type TaskRunner struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	results chan func() error
+	wg      sync.WaitGroup
+}
+
+func NewTaskRunner() *TaskRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &TaskRunner{
+		ctx:     ctx,
+		cancel:  cancel,
+		results: make(chan func() error, TASK_RESULT_BUFFER_SIZE),
+	}
+}
+
+func (r *TaskRunner) Submit(f TaskFunc, done TaskDone) *Task {
+	ctx, cancel := context.WithCancel(r.ctx)
+	t := &Task{cancel: cancel}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer cancel()
+
+		res, err := f(ctx)
+		if done == nil {
+			return
+		}
+
+		r.Post(func() error {
+			return done(res, err)
+		})
+	}()
+
+	return t
+}
+
+// Post queues f to run on the update goroutine. It may be called from any
+// goroutine and blocks only while the queue is full.
+func (r *TaskRunner) Post(f func() error) {
+	select {
+	case r.results <- f:
+	case <-r.ctx.Done():
+	}
+}
+
+// Update runs the callbacks queued since the last frame.
+func (r *TaskRunner) Update() error {
+	for {
+		select {
+		case f := <-r.results:
+			if err := f(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// Shutdown cancels every running task and waits for them to return.
+func (r *TaskRunner) Shutdown() {
+	r.cancel()
+	r.wg.Wait()
+}
@@ -0,0 +1,312 @@
+package gamelog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LOGS_DIR_NAME = "logs"
+	LOG_FILE_EXT  = ".log"
+	// SESSION_TIME_FORMAT names a session's log after when it started.
+	SESSION_TIME_FORMAT = "20060102-150405"
+)
+
+const (
+	// MAX_LOG_FILE_SIZE is how large a log grows before it is rotated.
+	MAX_LOG_FILE_SIZE = 4 << 20
+	// MAX_LOG_BACKUPS is how many rotated files are kept per session.
+	MAX_LOG_BACKUPS = 2
+	// MAX_SESSIONS is how many sessions are kept per game.
+	MAX_SESSIONS = 10
+)
+
+// Session is the log of one run of a game. Rotated output sits next to it
+// as <path>.1, <path>.2 and so on, newest first.
+type Session struct {
+	Path      string
+	StartedAt time.Time
+	Size      int64
+	// seq orders sessions started within the same second.
+	seq int
+}
+
+// Dir holds the logs of the game with gameID under the launcher's data dir.
+func Dir(root string, gameID int) string {
+	return filepath.Join(root, LOGS_DIR_NAME, strconv.Itoa(gameID))
+}
+
+// Sessions lists the sessions logged in dir, newest first.
+func Sessions(dir string) ([]Session, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != LOG_FILE_EXT {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(e.Name(), LOG_FILE_EXT)
+		seq := 0
+		if i := strings.IndexByte(stamp, '_'); i >= 0 {
+			seq, _ = strconv.Atoi(stamp[i+1:])
+			stamp = stamp[:i]
+		}
+		started, err := time.ParseInLocation(SESSION_TIME_FORMAT, stamp, time.Local)
+		if err != nil {
+			started = info.ModTime()
+		}
+
+		sessions = append(sessions, Session{
+			Path:      filepath.Join(dir, e.Name()),
+			StartedAt: started,
+			Size:      info.Size(),
+			seq:       seq,
+		})
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].StartedAt.Equal(sessions[j].StartedAt) {
+			return sessions[i].seq > sessions[j].seq
+		}
+		return sessions[i].StartedAt.After(sessions[j].StartedAt)
+	})
+
+	return sessions, nil
+}
+
+// Writer is a session's log file. Games are given the file itself as their
+// stdout and stderr rather than a pipe, so their output does not depend on
+// the launcher staying up to copy it. The file is opened for appending, which
+// lets the launcher add its own lines and truncate it under a running game.
+type Writer struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// Open starts a new session in dir and removes the oldest ones beyond
+// MAX_SESSIONS.
+func Open(dir string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	sessions, err := Sessions(dir)
+	if err != nil {
+		return nil, err
+	}
+	pruneSessions(sessions, MAX_SESSIONS-1)
+
+	// Games launched within the same second get a numbered name that sorts
+	// after the ones before them.
+	stamp := time.Now().Format(SESSION_TIME_FORMAT)
+	next := 0
+	for _, s := range sessions {
+		if s.StartedAt.Format(SESSION_TIME_FORMAT) == stamp {
+			next = max(next, s.seq+1)
+		}
+	}
+
+	for i := next; ; i++ {
+		name := stamp + LOG_FILE_EXT
+		if i > 0 {
+			name = fmt.Sprintf("%s_%d%s", stamp, i, LOG_FILE_EXT)
+		}
+		path := filepath.Join(dir, name)
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &Writer{path: path, f: f}, nil
+	}
+}
+
+// Reopen continues the session logged at path, for a game that outlived the
+// launcher that started it.
+func Reopen(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{path: path, f: f}, nil
+}
+
+// Path is the file the session is written to.
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// File is the session's open log file, to be handed to the game as its
+// stdout and stderr.
+func (w *Writer) File() *os.File {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.f
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	return w.f.Write(p)
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	err := w.f.Close()
+	w.f = nil
+
+	return err
+}
+
+// Rotate copies the log to <path>.1, shifting older copies up and dropping
+// the oldest, and truncates it once it has reached MAX_LOG_FILE_SIZE. The
+// game keeps writing to the same file throughout, so output written between
+// the copy and the truncate is lost; a failed rotation only leaves the log
+// growing.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+
+	info, err := w.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < MAX_LOG_FILE_SIZE {
+		return nil
+	}
+
+	os.Remove(backupPath(w.path, MAX_LOG_BACKUPS))
+	for i := MAX_LOG_BACKUPS - 1; i >= 1; i-- {
+		os.Rename(backupPath(w.path, i), backupPath(w.path, i+1))
+	}
+	if err = copyFile(w.path, backupPath(w.path, 1)); err != nil {
+		return err
+	}
+
+	// The file is open for appending only, which cannot be truncated on
+	// every platform, so go through the path.
+	return os.Truncate(w.path, 0)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// pruneSessions removes all but the newest keep sessions.
+func pruneSessions(sessions []Session, keep int) {
+	if len(sessions) <= keep {
+		return
+	}
+
+	for _, s := range sessions[keep:] {
+		os.Remove(s.Path)
+		for i := 1; i <= MAX_LOG_BACKUPS; i++ {
+			os.Remove(backupPath(s.Path, i))
+		}
+	}
+}
+
+// ReadTail returns the lines in the last max bytes of the log at path. A
+// line cut in half by the limit is left out.
+func ReadTail(path string, max int64) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := info.Size() - max
+	if offset < 0 {
+		offset = 0
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for s.Scan() {
+		lines = append(lines, strings.TrimRight(s.Text(), "\r"))
+	}
+
+	return lines, s.Err()
+}
@@ -0,0 +1,215 @@
+package installdb
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DB_FILE_NAME = "installs.json"
+)
+
+// File is one file of an installed game. Path is relative to the record's
+// InstallPath and always uses forward slashes.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Record describes what is installed for one game.
+type Record struct {
+	GameID    int    `json:"game_id"`
+	Tag       string `json:"tag"`
+	Version   string `json:"version"`
+	AssetName string `json:"asset_name"`
+	// ArchiveSHA256 is the hash of the archive the install was extracted
+	// from. ChecksumChecked notes whether it matched a published checksum.
+	ArchiveSHA256    string `json:"archive_sha256"`
+	ChecksumChecked  bool   `json:"checksum_checked"`
+	SignatureChecked bool   `json:"signature_checked"`
+	// Library is the library folder the game is installed in. InstallPath
+	// is the game's bundle directory and Executable is relative to it.
+	Library     string    `json:"library,omitempty"`
+	InstallPath string    `json:"install_path"`
+	Executable  string    `json:"executable"`
+	Files       []File    `json:"files"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// DB is the launcher's record of installed games, persisted as a single JSON
+// file. It is safe for concurrent use.
+type DB struct {
+	mu      sync.Mutex
+	path    string
+	records map[int]Record
+}
+
+type dbFile struct {
+	Games map[int]Record `json:"games"`
+}
+
+// Open loads the database at path. A missing file yields an empty database
+// that is created on the first change.
+func Open(path string) (*DB, error) {
+	d := &DB{
+		path:    path,
+		records: make(map[int]Record),
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var file dbFile
+	if err = json.NewDecoder(f).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	if file.Games != nil {
+		d.records = file.Games
+	}
+
+	return d, nil
+}
+
+// Get returns the record for a game. Its Files must not be modified.
+func (d *DB) Get(gameID int) (Record, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	r, ok := d.records[gameID]
+	return r, ok
+}
+
+// Records returns every record ordered by game ID.
+func (d *DB) Records() []Record {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	records := make([]Record, 0, len(d.records))
+	for _, r := range d.records {
+		records = append(records, r)
+	}
+
+	sort.Slice(records, func(a, b int) bool {
+		return records[a].GameID < records[b].GameID
+	})
+
+	return records
+}
+
+// Put stores the record for r.GameID. The database is unchanged if it
+// cannot be saved.
+func (d *DB) Put(r Record) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old, had := d.records[r.GameID]
+	d.records[r.GameID] = r
+
+	if err := d.save(); err != nil {
+		if had {
+			d.records[r.GameID] = old
+		} else {
+			delete(d.records, r.GameID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the record for a game.
+func (d *DB) Delete(gameID int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	old, had := d.records[gameID]
+	if !had {
+		return nil
+	}
+	delete(d.records, gameID)
+
+	if err := d.save(); err != nil {
+		d.records[gameID] = old
+		return err
+	}
+
+	return nil
+}
+
+// save writes the database to a temporary file and renames it into place.
+// d.mu must be held.
+func (d *DB) save() error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), DB_FILE_NAME+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(dbFile{Games: d.records}); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), d.path)
+}
+
+// ReadRecord reads a single record saved with WriteRecord.
+func ReadRecord(path string) (*Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var record Record
+	if err = json.NewDecoder(f).Decode(&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// WriteRecord saves a single record to path atomically. Installs keep a copy
+// of their record next to the bundle so it travels with it.
+func WriteRecord(path string, record Record) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err = json.NewEncoder(f).Encode(record); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
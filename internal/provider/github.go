@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/google/go-github/v62/github"
+)
+
+type GitHubProvider struct {
+	client *github.Client
+}
+
+var _ ReleaseProvider = (*GitHubProvider)(nil)
+
+func NewGitHubProvider(client *github.Client) *GitHubProvider {
+	return &GitHubProvider{
+		client: client,
+	}
+}
+
+func (p *GitHubProvider) ListReleases(ctx context.Context, g requests.Game) ([]Release, error) {
+	var releases []Release
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		rs, resp, err := p.client.Repositories.ListReleases(ctx, g.RepoOwner, g.RepoName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range rs {
+			if r.GetDraft() {
+				continue
+			}
+			releases = append(releases, fromGitHubRelease(r))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return releases, nil
+}
+
+func (p *GitHubProvider) GetLatestRelease(ctx context.Context, g requests.Game) (*Release, error) {
+	r, _, err := p.client.Repositories.GetLatestRelease(ctx, g.RepoOwner, g.RepoName)
+	if err != nil {
+		return nil, err
+	}
+
+	release := fromGitHubRelease(r)
+
+	return &release, nil
+}
+
+func (p *GitHubProvider) ResolveAssetDownload(ctx context.Context, g requests.Game, a Asset) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+}
+
+func fromGitHubRelease(r *github.RepositoryRelease) Release {
+	release := Release{
+		Tag:         r.GetTagName(),
+		Name:        r.GetName(),
+		Prerelease:  r.GetPrerelease(),
+		PublishedAt: r.GetPublishedAt().Time,
+	}
+
+	for _, a := range r.Assets {
+		release.Assets = append(release.Assets, Asset{
+			Name: a.GetName(),
+			URL:  a.GetBrowserDownloadURL(),
+			Size: int64(a.GetSize()),
+		})
+	}
+
+	return release
+}
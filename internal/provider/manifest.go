@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+// ManifestProvider reads releases from a JSON document served over HTTP, for
+// studios that host builds on their own web server or a Gitea instance.
+// Asset URLs may be relative to the manifest URL.
+//
+//	{
+//	  "releases": [{
+//	    "tag": "v1.2.0",
+//	    "name": "Spring update",
+//	    "prerelease": false,
+//	    "published_at": "2024-05-01T12:00:00Z",
+//	    "assets": [{"name": "game-linux-amd64.tar.gz", "url": "builds/game-linux-amd64.tar.gz", "size": 1024, "install_size": 4096}]
+//	  }]
+//	}
+type ManifestProvider struct {
+	client *http.Client
+}
+
+var _ ReleaseProvider = (*ManifestProvider)(nil)
+
+type manifest struct {
+	Releases []manifestRelease `json:"releases"`
+}
+
+type manifestRelease struct {
+	Tag         string          `json:"tag"`
+	Name        string          `json:"name"`
+	Prerelease  bool            `json:"prerelease"`
+	PublishedAt time.Time       `json:"published_at"`
+	Assets      []manifestAsset `json:"assets"`
+}
+
+type manifestAsset struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Size        int64  `json:"size"`
+	InstallSize int64  `json:"install_size,omitempty"`
+}
+
+func NewManifestProvider(client *http.Client) *ManifestProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &ManifestProvider{
+		client: client,
+	}
+}
+
+func (p *ManifestProvider) ListReleases(ctx context.Context, g requests.Game) ([]Release, error) {
+	if g.ManifestURL == "" {
+		return nil, fmt.Errorf("game %s has no manifest url", g.Name)
+	}
+
+	base, err := url.Parse(g.ManifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.ManifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch manifest: %s", res.Status)
+	}
+
+	var m manifest
+	if err = json.NewDecoder(res.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(m.Releases))
+	for _, r := range m.Releases {
+		release := Release{
+			Tag:         r.Tag,
+			Name:        r.Name,
+			Prerelease:  r.Prerelease,
+			PublishedAt: r.PublishedAt,
+		}
+
+		for _, a := range r.Assets {
+			u, err := base.Parse(a.URL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse url of asset %s: %w", a.Name, err)
+			}
+
+			release.Assets = append(release.Assets, Asset{
+				Name:        a.Name,
+				URL:         u.String(),
+				Size:        a.Size,
+				InstallSize: a.InstallSize,
+			})
+		}
+
+		releases = append(releases, release)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].PublishedAt.After(releases[j].PublishedAt)
+	})
+
+	return releases, nil
+}
+
+func (p *ManifestProvider) GetLatestRelease(ctx context.Context, g requests.Game) (*Release, error) {
+	releases, err := p.ListReleases(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range releases {
+		if !r.Prerelease {
+			return &r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to find a release in manifest for %s", g.Name)
+}
+
+func (p *ManifestProvider) ResolveAssetDownload(ctx context.Context, g requests.Game, a Asset) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+}
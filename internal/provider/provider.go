@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	PROVIDER_GITHUB   = "github"
+	PROVIDER_MANIFEST = "manifest"
+)
+
+type Asset struct {
+	Name string
+	URL  string
+	Size int64
+	// InstallSize is the declared size of the asset once extracted, or zero
+	// when the provider does not know it.
+	InstallSize int64
+}
+
+type Release struct {
+	Tag         string
+	Name        string
+	Prerelease  bool
+	PublishedAt time.Time
+	Assets      []Asset
+}
+
+// ReleaseProvider is a source of game builds. Implementations translate their
+// backend's release metadata into Release values and know how to fetch an
+// asset's bytes.
+type ReleaseProvider interface {
+	ListReleases(ctx context.Context, g requests.Game) ([]Release, error)
+	GetLatestRelease(ctx context.Context, g requests.Game) (*Release, error)
+	ResolveAssetDownload(ctx context.Context, g requests.Game, a Asset) (*http.Request, error)
+}
+
+// Registry maps the provider named by a game's registry entry to its
+// implementation.
+type Registry map[string]ReleaseProvider
+
+func (r Registry) For(g requests.Game) (ReleaseProvider, error) {
+	name := g.Provider
+	if name == "" {
+		name = PROVIDER_GITHUB
+	}
+
+	p, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown release provider: %s", name)
+	}
+
+	return p, nil
+}
@@ -1,12 +1,24 @@
 package requests
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+
+	"github.com/DillonEnge/keizai-launcher/internal/signing"
+)
+
+const (
+	SIGNATURE_HEADER = "X-Engehost-Signature"
 )
 
 type Client struct {
 	URL string
+	// PublicKeys, when set, require the registry to sign its responses with
+	// an Ed25519 signature of the body in SIGNATURE_HEADER.
+	PublicKeys signing.Keyring
 }
 
 type Game struct {
@@ -16,6 +28,20 @@ type Game struct {
 	RepoOwner          string `json:"repo_owner"`
 	IconURL            string `json:"icon_url"`
 	BackgroundImageURL string `json:"background_image_url"`
+	Provider           string `json:"provider"`
+	ManifestURL        string `json:"manifest_url"`
+	// PublicKeys are base64 Ed25519 keys the publisher signs releases with.
+	// They are dropped from responses the client could not verify.
+	PublicKeys []string `json:"public_keys"`
+	// LaunchArgs, LaunchEnv and LaunchDir are the publisher's defaults for
+	// starting the game. LaunchDir is relative to the installed bundle.
+	LaunchArgs []string          `json:"launch_args"`
+	LaunchEnv  map[string]string `json:"launch_env"`
+	LaunchDir  string            `json:"launch_dir"`
+	// DataDirs name the directories the game keeps saves and settings in,
+	// beneath the platform's per-user data dirs. Uninstalling only offers to
+	// delete these; nothing is guessed from the game's name.
+	DataDirs []string `json:"data_dirs"`
 }
 
 func (c *Client) GetGames() ([]Game, error) {
@@ -25,8 +51,44 @@ func (c *Client) GetGames() ([]Game, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch games: %s", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
 
-	json.NewDecoder(res.Body).Decode(&games)
+	if len(c.PublicKeys) > 0 {
+		h := res.Header.Get(SIGNATURE_HEADER)
+		if h == "" {
+			return nil, fmt.Errorf("registry response: %w", signing.ErrUnsigned)
+		}
+
+		sig, err := signing.DecodeSignature([]byte(h))
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.PublicKeys.Verify(body, sig); err != nil {
+			return nil, fmt.Errorf("registry response: %w", err)
+		}
+	}
+
+	if err = json.NewDecoder(bytes.NewReader(body)).Decode(&games); err != nil {
+		return nil, err
+	}
+
+	// Without a registry key anyone able to alter the response could also
+	// supply the keys its releases are checked against.
+	if len(c.PublicKeys) == 0 {
+		for i := range games {
+			games[i].PublicKeys = nil
+		}
+	}
 
 	return games, nil
 }
@@ -0,0 +1,135 @@
+package semver
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"V1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{" v1.2.3 ", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"1", Version{Major: 1}},
+		{"v2.5", Version{Major: 2, Minor: 5}},
+		{"0.0.0", Version{}},
+		{"1.0.0-alpha", Version{Major: 1, Prerelease: []string{"alpha"}}},
+		{"1.0.0-rc.1", Version{Major: 1, Prerelease: []string{"rc", "1"}}},
+		{"1.0.0-x-y.0", Version{Major: 1, Prerelease: []string{"x-y", "0"}}},
+		{"1.0.0+build.5", Version{Major: 1, Build: []string{"build", "5"}}},
+		{"1.0.0-beta.2+exp.sha.5114f85", Version{Major: 1, Prerelease: []string{"beta", "2"}, Build: []string{"exp", "sha", "5114f85"}}},
+		{"1.0.0+001", Version{Major: 1, Build: []string{"001"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got.Major != tt.want.Major || got.Minor != tt.want.Minor || got.Patch != tt.want.Patch ||
+				!slices.Equal(got.Prerelease, tt.want.Prerelease) || !slices.Equal(got.Build, tt.want.Build) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"v",
+		"1.0.0.1",
+		"1.2.3.4.5",
+		"1.a.0",
+		"01.0.0",
+		"1.02.0",
+		"1..0",
+		"1.0.",
+		"-1.0.0",
+		"1.0.0-",
+		"1.0.0+",
+		"1.0.0-01",
+		"1.0.0-al_pha",
+		"1.0.0-alpha..1",
+		"1.0.0+bu!ld",
+		"1.0.0 beta",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if v, err := Parse(in); err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", in, v)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1", "1.0.0", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.0.0", "2.1.0", -1},
+		{"2.1.0", "2.1.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0+build", "1.0.0", 0},
+		// The precedence example from semver.org section 11.
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.1", "0.9.9", 1},
+		{"1.0.0-rc.1+b", "1.0.0-rc.1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, err := Parse(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Parse(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := a.Compare(b); got != tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := b.Compare(a); got != -tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[string]string{
+		"1":                    "v1.0.0",
+		"v1.2.3":               "v1.2.3",
+		"1.0.0-rc.1+build.7":   "v1.0.0-rc.1+build.7",
+		"2.0.0+20240501.linux": "v2.0.0+20240501.linux",
+	}
+
+	for in, want := range tests {
+		v, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBadSignature = errors.New("signature verification failed")
+	ErrUnsigned     = errors.New("missing signature")
+)
+
+// Keyring is a set of Ed25519 public keys, any of which may sign a message.
+type Keyring []ed25519.PublicKey
+
+// ParseKeyring decodes standard base64 encoded Ed25519 public keys.
+func ParseKeyring(keys ...string) (Keyring, error) {
+	k := make(Keyring, 0, len(keys))
+	for _, v := range keys {
+		if v == "" {
+			continue
+		}
+
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode public key: %w", err)
+		}
+		if len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key length %d", len(b))
+		}
+
+		k = append(k, ed25519.PublicKey(b))
+	}
+
+	return k, nil
+}
+
+// DecodeSignature accepts either a raw 64 byte signature or its base64
+// encoding, optionally surrounded by whitespace.
+func DecodeSignature(b []byte) ([]byte, error) {
+	if len(b) == ed25519.SignatureSize {
+		return b, nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+
+	return sig, nil
+}
+
+func (k Keyring) Verify(msg, sig []byte) error {
+	for _, pub := range k {
+		if ed25519.Verify(pub, msg, sig) {
+			return nil
+		}
+	}
+
+	return ErrBadSignature
+}
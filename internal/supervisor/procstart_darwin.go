@@ -0,0 +1,31 @@
+//go:build darwin
+
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// SZOMB is the kinfo_proc state of a process that has exited but not been
+// reaped.
+const SZOMB = 5
+
+func processStartTime(pid int) (time.Time, error) {
+	info, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The sysctl succeeds with an empty record for a pid that does not
+	// exist.
+	if int(info.Proc.P_pid) != pid {
+		return time.Time{}, fmt.Errorf("pid %d is not running", pid)
+	}
+	if info.Proc.P_stat == SZOMB {
+		return time.Time{}, fmt.Errorf("pid %d has exited", pid)
+	}
+
+	return time.Unix(info.Proc.P_starttime.Unix()), nil
+}
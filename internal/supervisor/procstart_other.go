@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package supervisor
+
+import (
+	"errors"
+	"time"
+)
+
+// processStartTime is not supported here, so games are never adopted.
+func processStartTime(pid int) (time.Time, error) {
+	return time.Time{}, errors.ErrUnsupported
+}
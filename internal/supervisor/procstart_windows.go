@@ -0,0 +1,36 @@
+//go:build windows
+
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// STILL_ACTIVE is the exit code Windows reports for a running process.
+const STILL_ACTIVE = 259
+
+func processStartTime(pid int) (time.Time, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err = windows.GetExitCodeProcess(h, &code); err != nil {
+		return time.Time{}, err
+	}
+	if code != STILL_ACTIVE {
+		return time.Time{}, fmt.Errorf("pid %d has exited", pid)
+	}
+
+	var created, exited, kernel, user windows.Filetime
+	if err = windows.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, created.Nanoseconds()), nil
+}
@@ -0,0 +1,415 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/gamelog"
+)
+
+const (
+	// EVENT_RUNNING is published once a game's process has started.
+	EVENT_RUNNING EventKind = "running"
+	// EVENT_EXITED is published when a game exits cleanly or is stopped.
+	EVENT_EXITED EventKind = "exited"
+	// EVENT_CRASHED is published when a game exits with a failure status or
+	// is killed by something other than Stop.
+	EVENT_CRASHED EventKind = "crashed"
+)
+
+const (
+	DEFAULT_STOP_TIMEOUT = 10 * time.Second
+	// LOG_ROTATE_INTERVAL is how often a running game's log is checked
+	// for rotation.
+	LOG_ROTATE_INTERVAL = 5 * time.Second
+	// POLL_INTERVAL is how often a game started by an earlier launcher is
+	// checked for having exited, since it cannot be waited on.
+	POLL_INTERVAL = time.Second
+	// START_TIME_TOLERANCE is how far a process's start time may be from
+	// the recorded one for it to be taken as the same process rather than
+	// a reused PID.
+	START_TIME_TOLERANCE = 3 * time.Second
+	RUNNING_FILE_NAME    = "running.json"
+)
+
+var (
+	ErrAlreadyRunning = errors.New("game is already running")
+	ErrNotRunning     = errors.New("game is not running")
+)
+
+type EventKind string
+
+type Event struct {
+	GameID int
+	PID    int
+	Kind   EventKind
+	// ExitCode is the process's exit status, or -1 if it was ended by a
+	// signal or is not known because an earlier launcher started it.
+	ExitCode int
+	// Err describes why a game crashed.
+	Err string
+	// LogPath is the session log the game's output went to.
+	LogPath string
+}
+
+// Process is a running game.
+type Process struct {
+	GameID    int
+	PID       int
+	StartedAt time.Time
+	// Stopping is set once Stop has been asked to end the process.
+	Stopping bool
+	LogPath  string
+}
+
+type process struct {
+	Process
+	proc *os.Process
+	// cmd is nil for processes adopted from an earlier launcher.
+	cmd  *exec.Cmd
+	log  *gamelog.Writer
+	done chan struct{}
+}
+
+// runningFile is what is saved of a running game so a later launcher can
+// find it again.
+type runningFile struct {
+	GameID    int       `json:"game_id"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	LogPath   string    `json:"log_path"`
+}
+
+// Supervisor starts games, keeps track of them by PID while they run and
+// reaps their exit status. At most one process runs per game, and each run's
+// stdout and stderr go to a new session log. Games outlive the launcher, so
+// the running ones are saved under the data dir and adopted again by Restore.
+type Supervisor struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	dataDir string
+	onEvent func(Event)
+	running map[int]*process
+}
+
+// New returns a supervisor that kills a game timeout after asking it to
+// stop and keeps game logs and the running games under dataDir. A timeout
+// of zero uses DEFAULT_STOP_TIMEOUT.
+func New(timeout time.Duration, dataDir string) *Supervisor {
+	if timeout <= 0 {
+		timeout = DEFAULT_STOP_TIMEOUT
+	}
+
+	return &Supervisor{
+		timeout: timeout,
+		dataDir: dataDir,
+		running: make(map[int]*process),
+	}
+}
+
+// LogDir is where the sessions of gameID are logged.
+func (s *Supervisor) LogDir(gameID int) string {
+	return gamelog.Dir(s.dataDir, gameID)
+}
+
+// Restore adopts the games a previous launcher started that are still
+// running. They cannot be waited on, so they are polled until they exit and
+// their exit status is not known.
+func (s *Supervisor) Restore() error {
+	b, err := os.ReadFile(filepath.Join(s.dataDir, RUNNING_FILE_NAME))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved []runningFile
+	if err = json.Unmarshal(b, &saved); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range saved {
+		if _, ok := s.running[v.GameID]; ok || !isRunning(v.PID, v.StartedAt) {
+			continue
+		}
+
+		proc, err := os.FindProcess(v.PID)
+		if err != nil {
+			continue
+		}
+
+		p := &process{
+			Process: Process{
+				GameID:    v.GameID,
+				PID:       v.PID,
+				StartedAt: v.StartedAt,
+				LogPath:   v.LogPath,
+			},
+			proc: proc,
+			done: make(chan struct{}),
+		}
+		// The log only gets the launcher's own lines, so a game is adopted
+		// even when it cannot be opened.
+		if log, err := gamelog.Reopen(v.LogPath); err == nil {
+			p.log = log
+			go rotateLog(p)
+		}
+		s.running[v.GameID] = p
+
+		go s.poll(p)
+	}
+
+	return s.save()
+}
+
+// SetOnEvent registers f to be called, from the goroutine that observed it,
+// whenever a game starts or exits.
+func (s *Supervisor) SetOnEvent(f func(Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onEvent = f
+}
+
+// Start runs cmd as the process of gameID.
+func (s *Supervisor) Start(gameID int, cmd *exec.Cmd) (Process, error) {
+	s.mu.Lock()
+
+	if _, ok := s.running[gameID]; ok {
+		s.mu.Unlock()
+		return Process{}, ErrAlreadyRunning
+	}
+
+	log, err := gamelog.Open(s.LogDir(gameID))
+	if err != nil {
+		s.mu.Unlock()
+		return Process{}, err
+	}
+
+	// The game writes to the log file directly, so nothing it prints has
+	// to pass through the launcher.
+	cmd.Stdout = log.File()
+	cmd.Stderr = log.File()
+
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(log, "engehost: failed to start %s: %v\n", cmd.Path, err)
+		log.Close()
+		s.mu.Unlock()
+		return Process{}, err
+	}
+
+	p := &process{
+		Process: Process{
+			GameID:    gameID,
+			PID:       cmd.Process.Pid,
+			StartedAt: time.Now(),
+			LogPath:   log.Path(),
+		},
+		proc: cmd.Process,
+		cmd:  cmd,
+		log:  log,
+		done: make(chan struct{}),
+	}
+	fmt.Fprintf(log, "engehost: started %q in %s (pid %d)\n", cmd.Args, cmd.Dir, p.PID)
+	s.running[gameID] = p
+	// The game is running either way; failing to save it only means a
+	// later launcher will not know about it.
+	s.save()
+	onEvent := s.onEvent
+
+	go s.wait(p)
+	go rotateLog(p)
+
+	s.mu.Unlock()
+
+	if onEvent != nil {
+		onEvent(Event{GameID: gameID, PID: p.PID, Kind: EVENT_RUNNING, LogPath: p.LogPath})
+	}
+
+	return p.Process, nil
+}
+
+// Running reports the process of gameID if it is running.
+func (s *Supervisor) Running(gameID int) (Process, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.running[gameID]
+	if !ok {
+		return Process{}, false
+	}
+
+	return p.Process, true
+}
+
+// Stop asks the game to exit and kills it if it is still running after the
+// supervisor's timeout. It returns without waiting for the game to exit.
+func (s *Supervisor) Stop(gameID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.running[gameID]
+	if !ok {
+		return ErrNotRunning
+	}
+	if p.Stopping {
+		return nil
+	}
+
+	if err := terminate(p.proc); err != nil {
+		return err
+	}
+	p.Stopping = true
+
+	go func() {
+		select {
+		case <-p.done:
+		case <-time.After(s.timeout):
+			p.proc.Kill()
+		}
+	}()
+
+	return nil
+}
+
+func (s *Supervisor) wait(p *process) {
+	err := p.cmd.Wait()
+	close(p.done)
+
+	s.mu.Lock()
+	delete(s.running, p.GameID)
+	s.save()
+	stopping := p.Stopping
+	onEvent := s.onEvent
+	s.mu.Unlock()
+
+	e := Event{
+		GameID:   p.GameID,
+		PID:      p.PID,
+		Kind:     EVENT_EXITED,
+		ExitCode: p.cmd.ProcessState.ExitCode(),
+		LogPath:  p.LogPath,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && stopping:
+		// Games asked to stop may exit however they like.
+	case errors.As(err, &exitErr):
+		e.Kind = EVENT_CRASHED
+		e.Err = exitErr.Error()
+	default:
+		e.Kind = EVENT_CRASHED
+		e.Err = fmt.Sprintf("failed to wait for process: %v", err)
+	}
+
+	fmt.Fprintf(p.log, "engehost: %s (%s)\n", e.Kind, p.cmd.ProcessState)
+	p.log.Close()
+
+	if onEvent != nil {
+		onEvent(e)
+	}
+}
+
+// rotateLog keeps the log of a running game within its size limit. Errors
+// are ignored since the game's output does not depend on rotation.
+func rotateLog(p *process) {
+	t := time.NewTicker(LOG_ROTATE_INTERVAL)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+			p.log.Rotate()
+		}
+	}
+}
+
+// poll waits for a process adopted by Restore to exit.
+func (s *Supervisor) poll(p *process) {
+	t := time.NewTicker(POLL_INTERVAL)
+	defer t.Stop()
+
+	for range t.C {
+		if !isRunning(p.PID, p.StartedAt) {
+			break
+		}
+	}
+	close(p.done)
+
+	s.mu.Lock()
+	delete(s.running, p.GameID)
+	s.save()
+	onEvent := s.onEvent
+	s.mu.Unlock()
+
+	if p.log != nil {
+		fmt.Fprintf(p.log, "engehost: %s (exit status unknown)\n", EVENT_EXITED)
+		p.log.Close()
+	}
+
+	if onEvent != nil {
+		onEvent(Event{GameID: p.GameID, PID: p.PID, Kind: EVENT_EXITED, ExitCode: -1, LogPath: p.LogPath})
+	}
+}
+
+// isRunning reports whether pid is still the process that was started at
+// startedAt, so a PID reused by something else is not mistaken for a game.
+func isRunning(pid int, startedAt time.Time) bool {
+	started, err := processStartTime(pid)
+	if err != nil {
+		return false
+	}
+
+	d := started.Sub(startedAt)
+	return d > -START_TIME_TOLERANCE && d < START_TIME_TOLERANCE
+}
+
+// save writes the running games to the data dir. It must be called with
+// s.mu held.
+func (s *Supervisor) save() error {
+	saved := make([]runningFile, 0, len(s.running))
+	for _, p := range s.running {
+		saved = append(saved, runningFile{
+			GameID:    p.GameID,
+			PID:       p.PID,
+			StartedAt: p.StartedAt,
+			LogPath:   p.LogPath,
+		})
+	}
+
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dataDir, RUNNING_FILE_NAME+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = json.NewEncoder(tmp).Encode(saved); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.dataDir, RUNNING_FILE_NAME))
+}
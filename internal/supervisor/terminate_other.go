@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package supervisor
+
+import "os"
+
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,12 @@
+//go:build linux || darwin
+
+package supervisor
+
+import (
+	"os"
+	"syscall"
+)
+
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
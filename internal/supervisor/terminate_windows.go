@@ -0,0 +1,11 @@
+//go:build windows
+
+package supervisor
+
+import "os"
+
+// terminate kills p outright, since Windows has no signal a process can
+// handle to shut down cleanly.
+func terminate(p *os.Process) error {
+	return p.Kill()
+}
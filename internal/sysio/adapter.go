@@ -0,0 +1,45 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+var (
+	ErrUnsupportedSystem = errors.New("unsupported system")
+)
+
+type Adapter interface {
+	GetInstallDirPath(g requests.Game) (string, error)
+	Libraries() ([]Library, error)
+	GetHomeDirPath() (string, error)
+	DownloadLatestRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, report ProgressFunc) (*string, error)
+	DownloadRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, tag string, report ProgressFunc) (*string, error)
+	InstallLatestRelease(ctx context.Context, filePath *string, g requests.Game, report ProgressFunc) error
+	CheckForGame(g requests.Game) (bool, error)
+	CheckLatest(ctx context.Context, p provider.ReleaseProvider, g requests.Game) (*UpdateStatus, error)
+	GetVersion(appPath string, g requests.Game) (*string, error)
+	GetExecutableName(appPath string, g requests.Game) (*string, error)
+	GameCommand(appPath string, g requests.Game) (*exec.Cmd, error)
+	LaunchOptions(g requests.Game) LaunchOptions
+	ListVersions(g requests.Game) ([]InstalledVersion, error)
+	RollbackVersion(g requests.Game, version string) error
+	VerifyGame(ctx context.Context, g requests.Game, report ProgressFunc) (*VerifyReport, error)
+	RepairGame(ctx context.Context, p provider.ReleaseProvider, g requests.Game, report ProgressFunc) (*VerifyReport, error)
+	Uninstall(g requests.Game, opts UninstallOptions) error
+	UserDataDirs(g requests.Game) ([]string, error)
+	MoveGame(ctx context.Context, g requests.Game, library string, report ProgressFunc) error
+	CheckSpace(ctx context.Context, p provider.ReleaseProvider, g requests.Game, tag string) error
+	ClearDownloadCache() (int64, error)
+	OpenFolder(path string) error
+}
+
+func NewSysio(cfg *config.Config, db *installdb.DB) (Adapter, error) {
+	return newAdapter(cfg, db)
+}
@@ -3,34 +3,18 @@
 package sysio
 
 import (
-	"errors"
-	"runtime"
+	"os/user"
 
-	"github.com/DillonEnge/keizai-launcher/internal/requests"
-	"github.com/google/go-github/v62/github"
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
 )
 
-var (
-	ErrUnsupportedSystem = errors.New("unsupported system")
-)
-
-type Adapter interface {
-	GetInstallDirPath() (string, error)
-	GetHomeDirPath() (string, error)
-	DownloadLatestRelease(client *github.Client, g requests.Game) (*string, error)
-	InstallLatestRelease(filePath *string, g requests.Game) error
-	CheckForGame(g requests.Game) (bool, error)
-	CheckLatest(client *github.Client, g requests.Game) (bool, error)
-	GetVersion(appPath string, g requests.Game) (*string, error)
-	GetExecutableName(appPath string, g requests.Game) (*string, error)
-	ExecuteGame(appPath string, g requests.Game) error
-}
-
-func NewSysio() (Adapter, error) {
-	switch runtime.GOOS {
-	case "darwin":
-		return &DarwinAdapter{}, nil
-	default:
-		return nil, ErrUnsupportedSystem
-	}
+func newAdapter(cfg *config.Config, db *installdb.DB) (Adapter, error) {
+	return NewCoreAdapter(DarwinLayout{}, func() (string, error) {
+		usr, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		return usr.HomeDir, nil
+	}, cfg, db), nil
 }
@@ -0,0 +1,17 @@
+//go:build linux
+
+package sysio
+
+import (
+	"os"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+)
+
+func newAdapter(cfg *config.Config, db *installdb.DB) (Adapter, error) {
+	return NewCoreAdapter(LinuxLayout{
+		DataHome:   os.Getenv("XDG_DATA_HOME"),
+		ConfigHome: os.Getenv("XDG_CONFIG_HOME"),
+	}, os.UserHomeDir, cfg, db), nil
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !windows && !linux
+
+package sysio
+
+import (
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+)
+
+func newAdapter(cfg *config.Config, db *installdb.DB) (Adapter, error) {
+	return nil, ErrUnsupportedSystem
+}
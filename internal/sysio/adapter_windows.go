@@ -3,34 +3,14 @@
 package sysio
 
 import (
-	"errors"
-	"runtime"
+	"os"
 
-	"github.com/DillonEnge/keizai-launcher/internal/requests"
-	"github.com/google/go-github/v62/github"
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
 )
 
-var (
-	ErrUnsupportedSystem = errors.New("unsupported system")
-)
-
-type Adapter interface {
-	GetInstallDirPath() (string, error)
-	GetHomeDirPath() (string, error)
-	DownloadLatestRelease(client *github.Client, g requests.Game) (*string, error)
-	InstallLatestRelease(filePath *string, g requests.Game) error
-	CheckForGame(g requests.Game) (bool, error)
-	CheckLatest(client *github.Client, g requests.Game) (bool, error)
-	GetVersion(appPath string, g requests.Game) (*string, error)
-	GetExecutableName(appPath string, g requests.Game) (*string, error)
-	ExecuteGame(appPath string, g requests.Game) error
-}
-
-func NewSysio() (Adapter, error) {
-	switch runtime.GOOS {
-	case "windows":
-		return &WindowsAdapter{}, nil
-	default:
-		return nil, ErrUnsupportedSystem
-	}
+func newAdapter(cfg *config.Config, db *installdb.DB) (Adapter, error) {
+	return NewCoreAdapter(WindowsLayout{AppData: os.Getenv("APPDATA")}, func() (string, error) {
+		return "C:", nil
+	}, cfg, db), nil
 }
@@ -0,0 +1,162 @@
+package sysio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	// STALE_PART_AGE is how long a partial download may sit untouched before
+	// it is given up on and removed.
+	STALE_PART_AGE = 7 * 24 * time.Hour
+	// CACHE_KEY_LENGTH is how many hex digits of the asset URL's hash prefix
+	// a cached file name.
+	CACHE_KEY_LENGTH = 12
+)
+
+func downloadCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, CACHE_DIR_NAME, DOWNLOADS_DIR_NAME), nil
+}
+
+// gameCacheDir holds everything downloaded for g.
+func gameCacheDir(g requests.Game) (string, error) {
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, strconv.Itoa(g.ID)), nil
+}
+
+// assetCachePath is where asset is downloaded to. Games are kept apart and
+// the name is prefixed with a hash of the asset's URL, so releases that
+// reuse an asset name never pick up each other's bytes.
+func assetCachePath(g requests.Game, asset *provider.Asset) (string, error) {
+	dir, err := gameCacheDir(g)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(asset.URL))
+	key := hex.EncodeToString(sum[:])[:CACHE_KEY_LENGTH]
+
+	return filepath.Join(dir, key+"-"+filepath.Base(asset.Name)), nil
+}
+
+// removeCachedAssets deletes everything downloaded for g.
+func removeCachedAssets(g requests.Game) error {
+	dir, err := gameCacheDir(g)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// removeStaleParts deletes partial downloads under dir that have not been
+// written to for STALE_PART_AGE. Downloads in progress are always recent.
+func removeStaleParts(dir string) {
+	cutoff := time.Now().Add(-STALE_PART_AGE)
+
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isPartFile(path) {
+			return nil
+		}
+
+		if info, err := d.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+func isPartFile(path string) bool {
+	return strings.HasSuffix(path, PART_FILE_EXT) || strings.HasSuffix(path, PART_FILE_EXT+PART_META_EXT)
+}
+
+// ClearDownloadCache deletes every downloaded file that is not waiting to
+// be installed and returns how many bytes were freed. It fails with
+// ErrCacheInUse while a download is running.
+func (c *CoreAdapter) ClearDownloadCache() (int64, error) {
+	if !c.cache.TryLock() {
+		return 0, ErrCacheInUse
+	}
+	defer c.cache.Unlock()
+
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	keep := make(map[string]bool, len(c.pending))
+	for path := range c.pending {
+		keep[filepath.Clean(path)] = true
+	}
+	c.mu.Unlock()
+
+	var reclaimed int64
+	var errs []error
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || keep[path] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+
+		if err = os.Remove(path); err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		reclaimed += info.Size()
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	removeEmptyDirs(dir)
+
+	return reclaimed, errors.Join(errs...)
+}
+
+// removeEmptyDirs removes the per-game directories left empty under dir.
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			// Remove fails on directories that still hold files.
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package sysio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/semver"
+)
+
+// selectRelease picks the release a game should be on according to the
+// channel configured for it.
+func (c *CoreAdapter) selectRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game) (*provider.Release, error) {
+	gc := c.config.Game(g.ID)
+
+	switch gc.Channel {
+	case config.CHANNEL_STABLE:
+		return p.GetLatestRelease(ctx, g)
+	case config.CHANNEL_BETA:
+		releases, err := p.ListReleases(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+
+		return newestRelease(releases, true)
+	case config.CHANNEL_PINNED:
+		if gc.PinnedTag == "" {
+			return nil, fmt.Errorf("game %s is pinned without a tag", g.Name)
+		}
+
+		return findRelease(ctx, p, g, gc.PinnedTag)
+	default:
+		return nil, fmt.Errorf("unknown release channel: %s", gc.Channel)
+	}
+}
+
+// findRelease looks up a release of g by its tag.
+func findRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, tag string) (*provider.Release, error) {
+	releases, err := p.ListReleases(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range releases {
+		if r.Tag == tag {
+			return &releases[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to find release %s for %s", tag, g.Name)
+}
+
+// newestRelease returns the highest semantic version in releases. Releases
+// whose tag and name are not versions are ignored.
+func newestRelease(releases []provider.Release, prerelease bool) (*provider.Release, error) {
+	var newest *provider.Release
+	var newestVer semver.Version
+
+	for i, r := range releases {
+		if r.Prerelease && !prerelease {
+			continue
+		}
+
+		v, err := releaseVersion(&r)
+		if err != nil {
+			continue
+		}
+
+		if newest == nil || v.Compare(newestVer) > 0 {
+			newest = &releases[i]
+			newestVer = v
+		}
+	}
+
+	if newest == nil {
+		return nil, fmt.Errorf("failed to find a versioned release")
+	}
+
+	return newest, nil
+}
@@ -0,0 +1,182 @@
+package sysio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/signing"
+)
+
+const (
+	CHECKSUMS_ASSET_NAME = "SHA256SUMS"
+	CHECKSUM_ASSET_EXT   = ".sha256"
+	SIGNATURE_ASSET_EXT  = ".sig"
+	// MAX_METADATA_ASSET_SIZE bounds checksum and signature downloads, which
+	// are read into memory.
+	MAX_METADATA_ASSET_SIZE = 1 << 20
+)
+
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// findChecksum returns the published SHA-256 of asset, or an empty string
+// when the release ships neither an <asset>.sha256 file nor a SHA256SUMS
+// file. The per-asset file is tried first and SHA256SUMS is the fallback.
+// When keys is not empty the checksum file must carry a detached signature
+// (<checksum file>.sig) made by one of them, which in turn authenticates the
+// archive.
+func findChecksum(ctx context.Context, p provider.ReleaseProvider, g requests.Game, release *provider.Release, asset *provider.Asset, keys signing.Keyring) (string, error) {
+	var found []string
+	for _, name := range []string{asset.Name + CHECKSUM_ASSET_EXT, CHECKSUMS_ASSET_NAME} {
+		v, ok := findAsset(release, name)
+		if !ok {
+			continue
+		}
+		found = append(found, name)
+
+		b, err := fetchMetadataAsset(ctx, p, g, v)
+		if err != nil {
+			return "", err
+		}
+
+		if len(keys) > 0 {
+			if err = verifySignatureAsset(ctx, p, g, release, v, b, keys); err != nil {
+				return "", err
+			}
+		}
+
+		if sum, ok := parseChecksums(b, asset.Name, name != CHECKSUMS_ASSET_NAME); ok {
+			return sum, nil
+		}
+	}
+
+	if len(found) > 0 {
+		return "", fmt.Errorf("failed to find checksum for %s in %s", asset.Name, strings.Join(found, ", "))
+	}
+
+	if len(keys) > 0 {
+		return "", fmt.Errorf("%s has no signed checksum: %w", asset.Name, signing.ErrUnsigned)
+	}
+
+	return "", nil
+}
+
+func findAsset(release *provider.Release, name string) (provider.Asset, bool) {
+	for _, v := range release.Assets {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return provider.Asset{}, false
+}
+
+func verifySignatureAsset(ctx context.Context, p provider.ReleaseProvider, g requests.Game, release *provider.Release, signed provider.Asset, b []byte, keys signing.Keyring) error {
+	for _, v := range release.Assets {
+		if v.Name != signed.Name+SIGNATURE_ASSET_EXT {
+			continue
+		}
+
+		raw, err := fetchMetadataAsset(ctx, p, g, v)
+		if err != nil {
+			return err
+		}
+
+		sig, err := signing.DecodeSignature(raw)
+		if err != nil {
+			return err
+		}
+
+		if err = keys.Verify(b, sig); err != nil {
+			return fmt.Errorf("%s: %w", signed.Name, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", signed.Name, signing.ErrUnsigned)
+}
+
+// parseChecksums reads sha256sum output ("<hex>  <name>" or "<hex> *<name>").
+// A bare digest is only taken as the checksum of name when bare is set, for
+// a per-asset file, and it is the file's only entry.
+func parseChecksums(b []byte, name string, bare bool) (string, bool) {
+	var entries [][]string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) > 0 {
+			entries = append(entries, fields)
+		}
+	}
+
+	if bare && len(entries) == 1 && len(entries[0]) == 1 && isSHA256Hex(entries[0][0]) {
+		return strings.ToLower(entries[0][0]), true
+	}
+
+	for _, fields := range entries {
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name && isSHA256Hex(fields[0]) {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+
+	return "", false
+}
+
+func isSHA256Hex(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+func fileSHA256(ctx context.Context, path string, report ProgressFunc) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	tracker := newProgressTracker(report, PHASE_VERIFYING, 0, info.Size())
+
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(h, tracker), ctxReader{ctx: ctx, r: f}); err != nil {
+		return "", err
+	}
+	tracker.flush()
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fetchMetadataAsset(ctx context.Context, p provider.ReleaseProvider, g requests.Game, a provider.Asset) ([]byte, error) {
+	req, err := p.ResolveAssetDownload(ctx, g, a)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", a.Name, resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, MAX_METADATA_ASSET_SIZE))
+}
@@ -0,0 +1,115 @@
+package sysio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	testSumA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	testSumB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestParseChecksums(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		bare bool
+		want string
+	}{
+		{"sha256sum line", testSumA + "  game.tar.gz\n", false, testSumA},
+		{"binary mode", testSumA + " *game.tar.gz\n", false, testSumA},
+		{"picks named entry", testSumA + "  other.zip\n" + testSumB + "  game.tar.gz\n", false, testSumB},
+		{"upper case", strings.ToUpper(testSumA) + "  game.tar.gz\n", false, testSumA},
+		{"bare per-asset", testSumA + "\n", true, testSumA},
+		{"bare in SHA256SUMS", testSumA + "\n", false, ""},
+		{"bare among entries", testSumA + "\n" + testSumB + "  other.zip\n", true, ""},
+		{"two bare digests", testSumA + "\n" + testSumB + "\n", true, ""},
+		{"other asset only", testSumA + "  other.zip\n", false, ""},
+		{"not hex", strings.Repeat("z", 64) + "  game.tar.gz\n", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseChecksums([]byte(tt.file), "game.tar.gz", tt.bare)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("parseChecksums = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+// fileProvider serves release assets from files keyed by asset name.
+type fileProvider struct {
+	url string
+}
+
+func (fileProvider) ListReleases(ctx context.Context, g requests.Game) ([]provider.Release, error) {
+	return nil, nil
+}
+
+func (fileProvider) GetLatestRelease(ctx context.Context, g requests.Game) (*provider.Release, error) {
+	return nil, nil
+}
+
+func (p fileProvider) ResolveAssetDownload(ctx context.Context, g requests.Game, a provider.Asset) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/"+a.Name, nil)
+}
+
+func TestFindChecksum(t *testing.T) {
+	files := map[string]string{
+		CHECKSUMS_ASSET_NAME:                   testSumB + "  other.zip\n",
+		"game.tar.gz" + CHECKSUM_ASSET_EXT:     testSumA + "\n",
+		"named.tar.gz" + CHECKSUM_ASSET_EXT:    testSumB + "  other.zip\n",
+		"fallback.tar.gz" + CHECKSUM_ASSET_EXT: testSumB + "  other.zip\n",
+	}
+	files[CHECKSUMS_ASSET_NAME] += testSumA + "  fallback.tar.gz\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := files[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(b))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		asset   string
+		assets  []string
+		want    string
+		wantErr bool
+	}{
+		// SHA256SUMS is listed first but does not name the asset.
+		{"per-asset file first", "game.tar.gz", []string{CHECKSUMS_ASSET_NAME, "game.tar.gz.sha256"}, testSumA, false},
+		{"falls back to SHA256SUMS", "fallback.tar.gz", []string{"fallback.tar.gz.sha256", CHECKSUMS_ASSET_NAME}, testSumA, false},
+		{"named nowhere", "named.tar.gz", []string{"named.tar.gz.sha256", CHECKSUMS_ASSET_NAME}, "", true},
+		{"no checksum files", "game.tar.gz", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := provider.Asset{Name: tt.asset}
+			release := &provider.Release{Assets: []provider.Asset{asset}}
+			for _, name := range tt.assets {
+				release.Assets = append(release.Assets, provider.Asset{Name: name})
+			}
+
+			got, err := findChecksum(context.Background(), fileProvider{url: srv.URL}, requests.Game{}, release, &asset, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findChecksum error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findChecksum = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
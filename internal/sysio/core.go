@@ -0,0 +1,353 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/archive"
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"github.com/DillonEnge/keizai-launcher/internal/semver"
+	"github.com/DillonEnge/keizai-launcher/internal/signing"
+)
+
+const (
+	INSTALL_RECORD_DIR = ".engehost"
+)
+
+// CoreAdapter implements Adapter for every platform. Everything that differs
+// between platforms lives in its InstallLayout.
+type CoreAdapter struct {
+	layout  InstallLayout
+	homeDir func() (string, error)
+	config  *config.Config
+	db      *installdb.DB
+	mu      sync.Mutex
+	// swap serialises changes to what is installed.
+	swap sync.Mutex
+	// cache is held for reading by downloads and for writing while the
+	// download cache is cleared.
+	cache sync.RWMutex
+	// pending maps a downloaded archive to what is known about it from the
+	// release so it can be verified and recorded once it is installed.
+	pending map[string]pendingInstall
+}
+
+type pendingInstall struct {
+	tag     string
+	version string
+	asset   string
+	sha256  string
+	signed  bool
+}
+
+var _ Adapter = (*CoreAdapter)(nil)
+
+func NewCoreAdapter(layout InstallLayout, homeDir func() (string, error), cfg *config.Config, db *installdb.DB) *CoreAdapter {
+	return &CoreAdapter{
+		layout:  layout,
+		homeDir: homeDir,
+		config:  cfg,
+		db:      db,
+		pending: make(map[string]pendingInstall),
+	}
+}
+
+// GetInstallDirPath returns the library g is installed in, or the one it
+// will be installed into next.
+func (c *CoreAdapter) GetInstallDirPath(g requests.Game) (string, error) {
+	if record, ok := c.db.Get(g.ID); ok {
+		if record.Library != "" {
+			return record.Library, nil
+		}
+		// Records from before libraries existed: every layout keeps the
+		// bundle directly inside the install dir.
+		return filepath.Dir(record.InstallPath), nil
+	}
+
+	if lib := c.config.Game(g.ID).Library; lib != "" && c.isLibrary(lib) {
+		return lib, nil
+	}
+
+	return c.defaultLibrary()
+}
+
+func (c *CoreAdapter) GetHomeDirPath() (string, error) {
+	return c.homeDir()
+}
+
+func (c *CoreAdapter) DownloadLatestRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, report ProgressFunc) (*string, error) {
+	release, err := c.selectRelease(ctx, p, g)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.downloadRelease(ctx, p, g, release, report)
+}
+
+// DownloadRelease downloads a specific release of g regardless of its
+// channel, so older releases can be reinstalled.
+func (c *CoreAdapter) DownloadRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, tag string, report ProgressFunc) (*string, error) {
+	release, err := findRelease(ctx, p, g, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.downloadRelease(ctx, p, g, release, report)
+}
+
+func (c *CoreAdapter) downloadRelease(ctx context.Context, p provider.ReleaseProvider, g requests.Game, release *provider.Release, report ProgressFunc) (*string, error) {
+	asset, err := findPlatformAsset(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkSpace(g, asset); err != nil {
+		return nil, err
+	}
+
+	keys, err := c.publisherKeys(g)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := findChecksum(ctx, p, g, release, asset, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.RLock()
+	filePath, err := downloadAsset(ctx, p, g, asset, report)
+	if err != nil {
+		c.cache.RUnlock()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Registering the archive as pending before releasing the cache keeps
+	// a clear from deleting it before it is installed.
+	defer c.cache.RUnlock()
+
+	c.pending[filePath] = pendingInstall{
+		tag:     release.Tag,
+		version: releaseVersionString(release),
+		asset:   asset.Name,
+		sha256:  sum,
+		signed:  len(keys) > 0,
+	}
+
+	return &filePath, nil
+}
+
+func (c *CoreAdapter) InstallLatestRelease(ctx context.Context, filePath *string, g requests.Game, report ProgressFunc) error {
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	pending := c.pending[*filePath]
+	c.mu.Unlock()
+
+	sum, err := fileSHA256(ctx, *filePath, report)
+	if err != nil {
+		return err
+	}
+
+	if pending.sha256 != "" && sum != pending.sha256 {
+		os.Remove(*filePath)
+		c.forgetPending(*filePath)
+		return fmt.Errorf("%w: %s has sha256 %s, release published %s", ErrChecksumMismatch, *filePath, sum, pending.sha256)
+	}
+
+	record := installdb.Record{
+		GameID:           g.ID,
+		Library:          path,
+		Tag:              pending.tag,
+		Version:          pending.version,
+		AssetName:        pending.asset,
+		ArchiveSHA256:    sum,
+		ChecksumChecked:  pending.sha256 != "",
+		SignatureChecked: pending.signed,
+		InstalledAt:      time.Now(),
+	}
+
+	staging, err := c.stageInstall(ctx, *filePath, path, g, &record, report)
+	if staging != "" {
+		defer os.RemoveAll(staging)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = c.commitInstall(path, staging, g, record); err != nil {
+		return err
+	}
+
+	c.forgetPending(*filePath)
+
+	err = os.Remove(*filePath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckForGame reports whether g is installed. Games installed before the
+// install database existed are found by looking for their bundle.
+func (c *CoreAdapter) CheckForGame(g requests.Game) (bool, error) {
+	bundle := ""
+	if record, ok := c.db.Get(g.ID); ok {
+		bundle = record.InstallPath
+	} else {
+		path, err := c.GetInstallDirPath(g)
+		if err != nil {
+			return false, err
+		}
+		bundle = c.layout.BundleDir(path, g)
+	}
+
+	info, err := os.Stat(bundle)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return info.IsDir(), nil
+}
+
+func (c *CoreAdapter) CheckLatest(ctx context.Context, p provider.ReleaseProvider, g requests.Game) (*UpdateStatus, error) {
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	ver, err := c.GetVersion(path, g)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := semver.Parse(*ver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse installed version: %w", err)
+	}
+
+	release, err := c.selectRelease(ctx, p, g)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := releaseVersion(release)
+	if err != nil {
+		return nil, err
+	}
+
+	status := newUpdateStatus(installed, latest)
+	status.Pinned = c.config.Game(g.ID).Channel == config.CHANNEL_PINNED
+	if record, ok := c.db.Get(g.ID); ok {
+		status.Unverified = !record.ChecksumChecked && !record.SignatureChecked
+	}
+
+	return status, nil
+}
+
+func (c *CoreAdapter) GetVersion(appPath string, g requests.Game) (*string, error) {
+	if record, ok := c.db.Get(g.ID); ok && record.Version != "" {
+		return &record.Version, nil
+	}
+
+	ver, err := c.layout.VersionSource(appPath, g).ReadVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ver, nil
+}
+
+func (c *CoreAdapter) GetExecutableName(appPath string, g requests.Game) (*string, error) {
+	exePath, err := c.executablePath(appPath, g)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(exePath)
+
+	return &name, nil
+}
+
+// OpenFolder shows path in the platform's file manager, creating it first so
+// there is something to show.
+func (c *CoreAdapter) OpenFolder(path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	args := c.layout.OpenCommand(path)
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Reap the file manager whenever it exits.
+	go cmd.Wait()
+
+	return nil
+}
+
+// executablePath prefers the executable recorded at install time and only
+// asks the layout for games the install database does not know about.
+func (c *CoreAdapter) executablePath(appPath string, g requests.Game) (string, error) {
+	if record, ok := c.db.Get(g.ID); ok && record.Executable != "" {
+		return filepath.Join(record.InstallPath, filepath.FromSlash(record.Executable)), nil
+	}
+
+	return c.layout.ExecutablePath(appPath, g)
+}
+
+func (c *CoreAdapter) forgetPending(filePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, filePath)
+}
+
+// publisherKeys combines the keys the registry lists for a game with the
+// ones configured locally for its repo owner. The registry's keys are only
+// present when its response was verified, see requests.Client.GetGames.
+func (c *CoreAdapter) publisherKeys(g requests.Game) (signing.Keyring, error) {
+	return signing.ParseKeyring(append(c.config.GetPublisherKeys(g.RepoOwner), g.PublicKeys...)...)
+}
+
+func findPlatformAsset(release *provider.Release) (*provider.Asset, error) {
+	var asset *provider.Asset
+	for i, v := range release.Assets {
+		if strings.Contains(v.Name, fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)) && archive.IsArchiveName(v.Name) {
+			asset = &release.Assets[i]
+		}
+	}
+
+	if asset == nil {
+		return nil, fmt.Errorf("failed to find asset with GOOS and GOARCH")
+	}
+
+	return asset, nil
+}
+
+func installRecordPath(installDir string, g requests.Game) string {
+	return filepath.Join(installDir, INSTALL_RECORD_DIR, strings.ToLower(g.Name)+".json")
+}
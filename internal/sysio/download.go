@@ -0,0 +1,204 @@
+package sysio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	CACHE_DIR_NAME     = "engehost"
+	DOWNLOADS_DIR_NAME = "downloads"
+	PART_FILE_EXT      = ".part"
+	PART_META_EXT      = ".json"
+)
+
+var (
+	ErrIncompleteDownload = errors.New("incomplete download")
+	ErrCacheInUse         = errors.New("downloads are in progress")
+)
+
+// partMeta is kept next to a .part file so a later attempt can tell whether
+// the bytes already on disk belong to the same remote file.
+type partMeta struct {
+	URL          string `json:"url"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// downloadAsset fetches asset into the download cache. Bytes are written to
+// <asset>.part and only renamed to their final name once the expected size
+// has arrived, so an interrupted download resumes with a Range request.
+func downloadAsset(ctx context.Context, p provider.ReleaseProvider, g requests.Game, asset *provider.Asset, report ProgressFunc) (string, error) {
+	cache, err := downloadCacheDir()
+	if err != nil {
+		return "", err
+	}
+	removeStaleParts(cache)
+
+	finalPath, err := assetCachePath(g, asset)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(finalPath), 0755); err != nil {
+		return "", err
+	}
+
+	partPath := finalPath + PART_FILE_EXT
+	metaPath := partPath + PART_META_EXT
+
+	if info, err := os.Stat(finalPath); err == nil && asset.Size > 0 && info.Size() == asset.Size {
+		return finalPath, nil
+	}
+
+	req, err := p.ResolveAssetDownload(ctx, g, *asset)
+	if err != nil {
+		return "", err
+	}
+
+	var offset int64
+	meta, err := readPartMeta(metaPath)
+	if err == nil && meta.URL == req.URL.String() && meta.Size == asset.Size {
+		if info, err := os.Stat(partPath); err == nil {
+			offset = info.Size()
+		}
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		switch {
+		case meta.ETag != "":
+			req.Header.Set("If-Range", meta.ETag)
+		case meta.LastModified != "":
+			req.Header.Set("If-Range", meta.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return "", fmt.Errorf("failed to resume %s: unexpected content range %q", asset.Name, resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range or the remote file changed.
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset > 0 && offset == asset.Size {
+			return finalPath, finishPart(partPath, metaPath, finalPath)
+		}
+		os.Remove(partPath)
+		os.Remove(metaPath)
+		return "", fmt.Errorf("failed to resume %s: %s", asset.Name, resp.Status)
+	default:
+		return "", fmt.Errorf("failed to download %s: %s", asset.Name, resp.Status)
+	}
+
+	total := asset.Size
+	if total <= 0 && resp.StatusCode == http.StatusOK && resp.ContentLength > 0 {
+		total = resp.ContentLength
+	}
+
+	err = writePartMeta(metaPath, partMeta{
+		URL:          req.URL.String(),
+		Size:         asset.Size,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	tracker := newProgressTracker(report, PHASE_DOWNLOADING, offset, total)
+
+	n, err := io.Copy(out, io.TeeReader(resp.Body, tracker))
+	tracker.flush()
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if total > 0 && offset+n != total {
+		return "", fmt.Errorf("%w: %s has %d of %d bytes", ErrIncompleteDownload, asset.Name, offset+n, total)
+	}
+
+	return finalPath, finishPart(partPath, metaPath, finalPath)
+}
+
+// ctxReader stops a long copy from a local file once ctx is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(b)
+}
+
+func finishPart(partPath, metaPath, finalPath string) error {
+	if err := os.Rename(partPath, finalPath); err != nil {
+		return err
+	}
+
+	if err := os.Remove(metaPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func readPartMeta(path string) (*partMeta, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var meta partMeta
+	if err = json.NewDecoder(f).Decode(&meta); err != nil {
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+func writePartMeta(path string, meta partMeta) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(meta)
+}
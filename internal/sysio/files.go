@@ -0,0 +1,77 @@
+package sysio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+)
+
+// hashFiles lists and hashes every regular file under root for the install
+// record.
+func hashFiles(ctx context.Context, root string, report ProgressFunc) ([]installdb.File, error) {
+	var files []installdb.File
+	var total int64
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, installdb.File{Path: filepath.ToSlash(rel), Size: info.Size()})
+		total += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := newProgressTracker(report, PHASE_INDEXING, 0, total)
+
+	for i := range files {
+		sum, err := hashFile(ctx, filepath.Join(root, filepath.FromSlash(files[i].Path)), tracker)
+		if err != nil {
+			return nil, err
+		}
+		files[i].SHA256 = sum
+	}
+	tracker.flush()
+
+	return files, nil
+}
+
+// hashFile returns the hex SHA-256 of a file, copying what it reads to
+// progress.
+func hashFile(ctx context.Context, path string, progress io.Writer) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(h, progress), ctxReader{ctx: ctx, r: f}); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package sysio
+
+func (v FileVersion) ReadVersion() (string, error) {
+	return "", ErrUnsupportedSystem
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package sysio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bi-zone/go-fileversion"
+)
+
+func (v FileVersion) ReadVersion() (string, error) {
+	f, err := fileversion.New(v.Path)
+	if err != nil {
+		return "", err
+	}
+
+	ver, ok := strings.CutSuffix(f.FixedInfo().FileVersion.String(), ".0")
+	if !ok {
+		return "", fmt.Errorf("failed to find trailing .0 in .exe fileversion")
+	}
+
+	return fmt.Sprintf("v%s", ver), nil
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !windows && !linux
+
+package sysio
+
+func volumeFreeSpace(path string) (int64, error) {
+	return 0, ErrUnsupportedSystem
+}
+
+func sameVolumePaths(a, b string) bool {
+	return false
+}
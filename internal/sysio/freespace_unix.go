@@ -0,0 +1,23 @@
+//go:build linux || darwin
+
+package sysio
+
+import "golang.org/x/sys/unix"
+
+func volumeFreeSpace(path string) (int64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+func sameVolumePaths(a, b string) bool {
+	var sa, sb unix.Stat_t
+	if unix.Stat(a, &sa) != nil || unix.Stat(b, &sb) != nil {
+		return false
+	}
+
+	return sa.Dev == sb.Dev
+}
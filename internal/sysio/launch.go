@@ -0,0 +1,93 @@
+package sysio
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+// LaunchOptions is how a game is started: the registry's defaults with the
+// user's options applied on top.
+type LaunchOptions struct {
+	Args []string
+	Env  map[string]string
+	// Dir is the working directory. Relative paths are taken from the
+	// installed bundle and empty means the executable's directory.
+	Dir string
+}
+
+// LaunchOptions merges the launch defaults the registry gives g with the
+// ones the user configured. User arguments follow the registry's, user
+// variables override the registry's and a user directory replaces the
+// registry's.
+func (c *CoreAdapter) LaunchOptions(g requests.Game) LaunchOptions {
+	gc := c.config.Game(g.ID)
+
+	opts := LaunchOptions{
+		Args: append(slices.Clone(g.LaunchArgs), gc.LaunchArgs...),
+		Env:  maps.Clone(g.LaunchEnv),
+		Dir:  g.LaunchDir,
+	}
+
+	if len(gc.LaunchEnv) > 0 && opts.Env == nil {
+		opts.Env = make(map[string]string, len(gc.LaunchEnv))
+	}
+	maps.Copy(opts.Env, gc.LaunchEnv)
+
+	if gc.LaunchDir != "" {
+		opts.Dir = gc.LaunchDir
+	}
+
+	return opts
+}
+
+// GameCommand builds the command that launches g with its launch options.
+// It is not started, so the caller can supervise it.
+func (c *CoreAdapter) GameCommand(appPath string, g requests.Game) (*exec.Cmd, error) {
+	exePath, err := c.executablePath(appPath, g)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := c.LaunchOptions(g)
+
+	cmd := exec.Command(exePath, opts.Args...)
+
+	switch {
+	case opts.Dir == "":
+		cmd.Dir = filepath.Dir(exePath)
+	case filepath.IsAbs(opts.Dir):
+		cmd.Dir = opts.Dir
+	default:
+		cmd.Dir = filepath.Join(c.bundlePath(appPath, g), opts.Dir)
+	}
+
+	if len(opts.Env) > 0 {
+		// Later entries win, so the overrides only need appending.
+		keys := make([]string, 0, len(opts.Env))
+		for k := range opts.Env {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		cmd.Env = os.Environ()
+		for _, k := range keys {
+			cmd.Env = append(cmd.Env, k+"="+opts.Env[k])
+		}
+	}
+
+	return cmd, nil
+}
+
+// bundlePath is where g is installed, preferring the install record.
+func (c *CoreAdapter) bundlePath(appPath string, g requests.Game) string {
+	if record, ok := c.db.Get(g.ID); ok {
+		return record.InstallPath
+	}
+
+	return c.layout.BundleDir(appPath, g)
+}
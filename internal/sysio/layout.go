@@ -0,0 +1,85 @@
+package sysio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+	"howett.net/plist"
+)
+
+// InstallLayout describes where a platform keeps an installed game. Layouts
+// only build paths and read files beneath them, so they can be exercised
+// against fixtures on any OS.
+type InstallLayout interface {
+	InstallDir(homeDir string) string
+	ExtractDir(installDir string, g requests.Game) string
+	BundleDir(installDir string, g requests.Game) string
+	ExecutablePath(installDir string, g requests.Game) (string, error)
+	VersionSource(installDir string, g requests.Game) VersionSource
+	// ShortcutPaths and UserDataDirs are where shortcuts to a game and the
+	// game's own saves and settings conventionally live. They are only
+	// consulted when a game is uninstalled. UserDataDirs only covers the
+	// game's DataDirs and skips any that are not a single path element.
+	ShortcutPaths(homeDir string, g requests.Game) []string
+	UserDataDirs(homeDir string, g requests.Game) []string
+	// OpenCommand shows path in the platform's file manager.
+	OpenCommand(path string) []string
+}
+
+type VersionSource interface {
+	ReadVersion() (string, error)
+}
+
+type PlistVersion struct {
+	Path string
+}
+
+func (p PlistVersion) ReadVersion() (string, error) {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var info struct {
+		CFBundleVersion string `plist:"CFBundleVersion"`
+	}
+
+	if err = plist.NewDecoder(f).Decode(&info); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("v%s", info.CFBundleVersion), nil
+}
+
+// FileVersion reads the version resource of a PE executable. It is only
+// readable on Windows.
+type FileVersion struct {
+	Path string
+}
+
+// RecordVersion reads the version from an install record saved next to the
+// bundle.
+type RecordVersion struct {
+	Path string
+}
+
+func (r RecordVersion) ReadVersion() (string, error) {
+	record, err := installdb.ReadRecord(r.Path)
+	if err != nil {
+		return "", err
+	}
+
+	return record.Version, nil
+}
+
+// isDataDirName reports whether name can be used as a directory beneath a
+// shared data dir. Names come from the registry, so anything that could
+// reach the data dir itself or outside it is refused.
+func isDataDirName(name string) bool {
+	return filepath.IsLocal(name) && name != "." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,262 @@
+package sysio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleExecutable</key>
+	<string>Keizai</string>
+	<key>CFBundleVersion</key>
+	<string>1.2.3</string>
+</dict>
+</plist>
+`
+
+// minimalELF is the smallest file debug/elf accepts as an executable: a
+// 64-bit header with no sections or program headers.
+func minimalELF() []byte {
+	b := make([]byte, 64)
+	copy(b, []byte{0x7f, 'E', 'L', 'F', 2, 1, 1})
+	binary.LittleEndian.PutUint16(b[16:], 2)  // ET_EXEC
+	binary.LittleEndian.PutUint16(b[18:], 62) // EM_X86_64
+	binary.LittleEndian.PutUint32(b[20:], 1)
+	binary.LittleEndian.PutUint16(b[52:], 64)
+	binary.LittleEndian.PutUint16(b[54:], 56)
+	binary.LittleEndian.PutUint16(b[58:], 64)
+	return b
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLayoutDirs(t *testing.T) {
+	installDir := filepath.Join(t.TempDir(), "library")
+	g := requests.Game{ID: 1, Name: "Keizai"}
+
+	tests := []struct {
+		name    string
+		layout  InstallLayout
+		extract string
+		bundle  string
+	}{
+		{"linux", LinuxLayout{}, filepath.Join(installDir, "keizai"), filepath.Join(installDir, "keizai")},
+		{"windows", WindowsLayout{}, installDir, filepath.Join(installDir, "keizai")},
+		{"darwin", DarwinLayout{}, installDir, filepath.Join(installDir, "Keizai.app")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.layout.ExtractDir(installDir, g); got != tt.extract {
+				t.Errorf("ExtractDir = %q, want %q", got, tt.extract)
+			}
+			if got := tt.layout.BundleDir(installDir, g); got != tt.bundle {
+				t.Errorf("BundleDir = %q, want %q", got, tt.bundle)
+			}
+		})
+	}
+}
+
+func TestLayoutExecutablePath(t *testing.T) {
+	g := requests.Game{ID: 1, Name: "Keizai"}
+
+	tests := []struct {
+		name   string
+		layout InstallLayout
+		// setup builds the fixture under installDir and returns the path
+		// the layout should find.
+		setup func(t *testing.T, installDir string) string
+	}{
+		{
+			name:   "linux record",
+			layout: LinuxLayout{},
+			setup: func(t *testing.T, installDir string) string {
+				exe := filepath.Join(installDir, "keizai", "bin", "run.sh")
+				writeFile(t, exe, []byte("#!/bin/sh\n"))
+				writeFile(t, filepath.Join(installDir, "keizai", "bin", "other"), minimalELF())
+
+				recordPath := installRecordPath(installDir, g)
+				if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				err := installdb.WriteRecord(recordPath, installdb.Record{GameID: g.ID, Executable: "bin/run.sh"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return exe
+			},
+		},
+		{
+			name:   "linux elf scan prefers game name",
+			layout: LinuxLayout{},
+			setup: func(t *testing.T, installDir string) string {
+				writeFile(t, filepath.Join(installDir, "keizai", "aaa"), minimalELF())
+				writeFile(t, filepath.Join(installDir, "keizai", "libfoo.so"), minimalELF())
+				writeFile(t, filepath.Join(installDir, "keizai", "readme.txt"), []byte("hi"))
+				exe := filepath.Join(installDir, "keizai", "Keizai.x86_64")
+				writeFile(t, exe, minimalELF())
+				return exe
+			},
+		},
+		{
+			name:   "windows",
+			layout: WindowsLayout{},
+			setup: func(t *testing.T, installDir string) string {
+				return filepath.Join(installDir, "keizai", "Keizai.exe")
+			},
+		},
+		{
+			name:   "darwin",
+			layout: DarwinLayout{},
+			setup: func(t *testing.T, installDir string) string {
+				writeFile(t, filepath.Join(installDir, "Keizai.app", "Contents", "Info.plist"), []byte(testInfoPlist))
+				return filepath.Join(installDir, "Keizai.app", "Contents", "MacOS", "Keizai")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir := t.TempDir()
+			want := tt.setup(t, installDir)
+
+			got, err := tt.layout.ExecutablePath(installDir, g)
+			if err != nil {
+				t.Fatalf("ExecutablePath: %v", err)
+			}
+			if got != want {
+				t.Errorf("ExecutablePath = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLayoutExecutablePathMissing(t *testing.T) {
+	g := requests.Game{ID: 1, Name: "Keizai"}
+
+	for name, layout := range map[string]InstallLayout{
+		"linux":  LinuxLayout{},
+		"darwin": DarwinLayout{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			installDir := t.TempDir()
+			if err := os.MkdirAll(layout.BundleDir(installDir, g), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if p, err := layout.ExecutablePath(installDir, g); err == nil {
+				t.Errorf("ExecutablePath = %q, want an error", p)
+			}
+		})
+	}
+}
+
+func TestLayoutVersionSource(t *testing.T) {
+	g := requests.Game{ID: 1, Name: "Keizai"}
+
+	t.Run("linux", func(t *testing.T) {
+		installDir := t.TempDir()
+		recordPath := installRecordPath(installDir, g)
+		if err := os.MkdirAll(filepath.Dir(recordPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := installdb.WriteRecord(recordPath, installdb.Record{GameID: g.ID, Version: "v2.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+
+		src := LinuxLayout{}.VersionSource(installDir, g)
+		if _, ok := src.(RecordVersion); !ok {
+			t.Fatalf("VersionSource = %T, want RecordVersion", src)
+		}
+
+		v, err := src.ReadVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "v2.0.0" {
+			t.Errorf("ReadVersion = %q, want v2.0.0", v)
+		}
+	})
+
+	t.Run("windows", func(t *testing.T) {
+		installDir := t.TempDir()
+
+		src := WindowsLayout{}.VersionSource(installDir, g)
+		fv, ok := src.(FileVersion)
+		if !ok {
+			t.Fatalf("VersionSource = %T, want FileVersion", src)
+		}
+		if want := filepath.Join(installDir, "keizai", "Keizai.exe"); fv.Path != want {
+			t.Errorf("FileVersion.Path = %q, want %q", fv.Path, want)
+		}
+	})
+
+	t.Run("darwin", func(t *testing.T) {
+		installDir := t.TempDir()
+		writeFile(t, filepath.Join(installDir, "Keizai.app", "Contents", "Info.plist"), []byte(testInfoPlist))
+
+		src := DarwinLayout{}.VersionSource(installDir, g)
+		if _, ok := src.(PlistVersion); !ok {
+			t.Fatalf("VersionSource = %T, want PlistVersion", src)
+		}
+
+		v, err := src.ReadVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "v1.2.3" {
+			t.Errorf("ReadVersion = %q, want v1.2.3", v)
+		}
+	})
+}
+
+func TestLayoutUserDataDirs(t *testing.T) {
+	home := t.TempDir()
+	layouts := map[string]InstallLayout{
+		"linux":   LinuxLayout{},
+		"windows": WindowsLayout{AppData: filepath.Join(home, "AppData", "Roaming")},
+		"darwin":  DarwinLayout{},
+	}
+
+	for name, layout := range layouts {
+		t.Run(name, func(t *testing.T) {
+			dirs := layout.UserDataDirs(home, requests.Game{Name: "Other", DataDirs: []string{"Keizai"}})
+			if len(dirs) == 0 {
+				t.Error("UserDataDirs(Keizai) is empty")
+			}
+			for _, dir := range dirs {
+				if filepath.Base(dir) != "Keizai" || !pathContains(home, dir) {
+					t.Errorf("UserDataDirs(Keizai) has %q", dir)
+				}
+			}
+
+			// The name alone is never taken as a data dir.
+			if dirs := layout.UserDataDirs(home, requests.Game{Name: "Keizai"}); len(dirs) > 0 {
+				t.Errorf("UserDataDirs without DataDirs = %q, want none", dirs)
+			}
+
+			for _, bad := range []string{"", ".", "..", "../Keizai", "Keizai/..", "a/b", `a\b`, "/etc"} {
+				if dirs := layout.UserDataDirs(home, requests.Game{DataDirs: []string{bad}}); len(dirs) > 0 {
+					t.Errorf("UserDataDirs(%q) = %q, want none", bad, dirs)
+				}
+			}
+		})
+	}
+}
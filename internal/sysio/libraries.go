@@ -0,0 +1,108 @@
+package sysio
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Library is a folder games can be installed into.
+type Library struct {
+	Path string
+	// Free is the space left on the library's volume in bytes, or -1 when it
+	// cannot be read.
+	Free    int64
+	Default bool
+}
+
+// Libraries returns the platform's default library followed by the ones
+// configured by the user.
+func (c *CoreAdapter) Libraries() ([]Library, error) {
+	def, err := c.defaultLibrary()
+	if err != nil {
+		return nil, err
+	}
+
+	libraries := []Library{{Path: def, Default: true}}
+	for _, l := range c.config.GetLibraries() {
+		if l != def {
+			libraries = append(libraries, Library{Path: l})
+		}
+	}
+
+	for i := range libraries {
+		free, err := freeSpace(libraries[i].Path)
+		if err != nil {
+			free = -1
+		}
+		libraries[i].Free = free
+	}
+
+	return libraries, nil
+}
+
+func (c *CoreAdapter) defaultLibrary() (string, error) {
+	p, err := c.GetHomeDirPath()
+	if err != nil {
+		return "", err
+	}
+
+	return c.layout.InstallDir(p), nil
+}
+
+// isLibrary reports whether path is still one of the configured libraries.
+func (c *CoreAdapter) isLibrary(path string) bool {
+	for _, l := range c.config.GetLibraries() {
+		if l == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// freeSpace reports the space available on the volume holding path. A
+// library that has not been created yet is measured at its nearest existing
+// parent.
+func freeSpace(path string) (int64, error) {
+	p, err := existingParent(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return volumeFreeSpace(p)
+}
+
+// sameVolume reports whether a and b are known to be on the same volume.
+func sameVolume(a, b string) bool {
+	pa, err := existingParent(a)
+	if err != nil {
+		return false
+	}
+
+	pb, err := existingParent(b)
+	if err != nil {
+		return false
+	}
+
+	return sameVolumePaths(pa, pb)
+}
+
+func existingParent(path string) (string, error) {
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,141 @@
+package sysio
+
+import (
+	"debug/elf"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	DEFAULT_DATA_DIR_LINUX   = ".local/share"
+	DEFAULT_CONFIG_DIR_LINUX = ".config"
+	INSTALL_DIR_NAME_LINUX   = "engehost"
+	APPLICATIONS_DIR_LINUX   = "applications"
+	DESKTOP_FILE_PREFIX      = "engehost-"
+	FILE_MANAGER_LINUX       = "xdg-open"
+)
+
+// LinuxLayout installs into the XDG data directory. DataHome and ConfigHome
+// mirror $XDG_DATA_HOME and $XDG_CONFIG_HOME and fall back to ~/.local/share
+// and ~/.config when unset or relative.
+type LinuxLayout struct {
+	DataHome   string
+	ConfigHome string
+}
+
+var _ InstallLayout = LinuxLayout{}
+
+func (l LinuxLayout) InstallDir(homeDir string) string {
+	return filepath.Join(l.dataHome(homeDir), INSTALL_DIR_NAME_LINUX)
+}
+
+func (l LinuxLayout) ExtractDir(installDir string, g requests.Game) string {
+	return l.BundleDir(installDir, g)
+}
+
+func (LinuxLayout) BundleDir(installDir string, g requests.Game) string {
+	return filepath.Join(installDir, strings.ToLower(g.Name))
+}
+
+// ExecutablePath prefers the executable noted in the install record and
+// falls back to scanning the bundle for ELF binaries.
+func (l LinuxLayout) ExecutablePath(installDir string, g requests.Game) (string, error) {
+	bundlePath := l.BundleDir(installDir, g)
+
+	if record, err := installdb.ReadRecord(installRecordPath(installDir, g)); err == nil && record.Executable != "" {
+		p := filepath.Join(bundlePath, filepath.FromSlash(record.Executable))
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+
+	return findELFExecutable(bundlePath, g)
+}
+
+func (LinuxLayout) VersionSource(installDir string, g requests.Game) VersionSource {
+	return RecordVersion{Path: installRecordPath(installDir, g)}
+}
+
+func (l LinuxLayout) ShortcutPaths(homeDir string, g requests.Game) []string {
+	return []string{filepath.Join(l.dataHome(homeDir), APPLICATIONS_DIR_LINUX, DESKTOP_FILE_PREFIX+strings.ToLower(g.Name)+".desktop")}
+}
+
+func (l LinuxLayout) UserDataDirs(homeDir string, g requests.Game) []string {
+	var dirs []string
+	for _, name := range g.DataDirs {
+		if isDataDirName(name) {
+			dirs = append(dirs, filepath.Join(l.dataHome(homeDir), name), filepath.Join(l.configHome(homeDir), name))
+		}
+	}
+
+	return dirs
+}
+
+func (l LinuxLayout) OpenCommand(path string) []string {
+	return []string{FILE_MANAGER_LINUX, path}
+}
+
+func (l LinuxLayout) dataHome(homeDir string) string {
+	if filepath.IsAbs(l.DataHome) {
+		return l.DataHome
+	}
+
+	return filepath.Join(homeDir, DEFAULT_DATA_DIR_LINUX)
+}
+
+func (l LinuxLayout) configHome(homeDir string) string {
+	if filepath.IsAbs(l.ConfigHome) {
+		return l.ConfigHome
+	}
+
+	return filepath.Join(homeDir, DEFAULT_CONFIG_DIR_LINUX)
+}
+
+// findELFExecutable walks the extracted game directory for ELF executables,
+// preferring one whose file name matches the game name.
+func findELFExecutable(gamePath string, g requests.Game) (string, error) {
+	var found []string
+
+	err := filepath.WalkDir(gamePath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || strings.Contains(d.Name(), ".so") {
+			return nil
+		}
+
+		f, err := elf.Open(p)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		if f.Type == elf.ET_EXEC || f.Type == elf.ET_DYN {
+			found = append(found, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range found {
+		name := strings.ToLower(filepath.Base(p))
+		if name == strings.ToLower(g.Name) || strings.TrimSuffix(name, filepath.Ext(name)) == strings.ToLower(g.Name) {
+			return p, nil
+		}
+	}
+
+	if len(found) == 0 {
+		return "", fmt.Errorf("failed to find ELF executable in %s", gamePath)
+	}
+
+	return found[0], nil
+}
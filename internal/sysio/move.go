@@ -0,0 +1,338 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+var (
+	ErrNotEnoughSpace = errors.New("not enough free space")
+	ErrTargetExists   = errors.New("target library already has a copy of the game")
+	ErrUnknownLibrary = errors.New("not a library")
+)
+
+// MoveGame moves an installed game and its kept versions into another
+// library. The files are copied and verified against the install record
+// before the record is switched over, and only then is the source removed,
+// so cancelling ctx at any point leaves the game where it was.
+func (c *CoreAdapter) MoveGame(ctx context.Context, g requests.Game, library string, report ProgressFunc) error {
+	if !filepath.IsAbs(library) {
+		return fmt.Errorf("%w: %s", config.ErrRelativeLibrary, library)
+	}
+	library = filepath.Clean(library)
+
+	libraries, err := c.Libraries()
+	if err != nil {
+		return err
+	}
+	known := false
+	for _, l := range libraries {
+		if filepath.Clean(l.Path) == library {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %s", ErrUnknownLibrary, library)
+	}
+
+	record, ok := c.db.Get(g.ID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotRecorded, g.Name)
+	}
+
+	source, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return err
+	}
+	if source == library {
+		return nil
+	}
+
+	c.swap.Lock()
+	defer c.swap.Unlock()
+
+	target := c.layout.BundleDir(library, g)
+	if _, err = os.Lstat(target); err == nil {
+		return fmt.Errorf("%w: %s", ErrTargetExists, target)
+	}
+
+	if err = os.MkdirAll(filepath.Join(library, INSTALL_RECORD_DIR), 0755); err != nil {
+		return err
+	}
+
+	moved := record
+	moved.Library = library
+	moved.InstallPath = target
+
+	sourceVersions := versionsDir(source, g)
+	targetVersions := versionsDir(library, g)
+
+	keptSize, err := dirSize(sourceVersions)
+	if errors.Is(err, fs.ErrNotExist) {
+		keptSize = -1
+	} else if err != nil {
+		return err
+	}
+
+	// A rename is enough when both libraries share a volume.
+	if err = os.Rename(record.InstallPath, target); err == nil {
+		if keptSize >= 0 {
+			if err = renameVersions(sourceVersions, targetVersions); err != nil {
+				os.Rename(target, record.InstallPath)
+				return err
+			}
+		}
+		if err = c.switchRecord(library, g, moved); err != nil {
+			if keptSize >= 0 {
+				os.Rename(targetVersions, sourceVersions)
+			}
+			os.Rename(target, record.InstallPath)
+			return err
+		}
+		return c.removeMoveSource(source, g)
+	}
+
+	var total int64
+	for _, f := range record.Files {
+		total += f.Size
+	}
+
+	// Records from before files were hashed can only be checked by the
+	// number and size of the files.
+	var sourceFiles int
+	if len(record.Files) == 0 {
+		if sourceFiles, total, err = treeStats(record.InstallPath); err != nil {
+			return err
+		}
+	}
+
+	if err = requireSpace(library, total+max(keptSize, 0)); err != nil {
+		return err
+	}
+
+	staging, err := newStaging(library, g)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	staged := c.layout.BundleDir(staging, g)
+	stagedVersions := filepath.Join(staging, VERSIONS_DIR_NAME)
+
+	tracker := newProgressTracker(report, PHASE_COPYING, 0, total+max(keptSize, 0))
+	if err = copyTree(ctx, record.InstallPath, staged, tracker); err != nil {
+		return err
+	}
+	if keptSize >= 0 {
+		if err = copyTree(ctx, sourceVersions, stagedVersions, tracker); err != nil {
+			return err
+		}
+	}
+	tracker.flush()
+
+	if len(record.Files) > 0 {
+		check := record
+		check.InstallPath = staged
+
+		result, err := verifyFiles(ctx, check, report)
+		if err != nil {
+			return err
+		}
+		if !result.OK() {
+			return fmt.Errorf("copy of %s failed verification: %s", g.Name, result)
+		}
+	} else {
+		files, size, err := treeStats(staged)
+		if err != nil {
+			return err
+		}
+		if files != sourceFiles || size != total {
+			return fmt.Errorf("copy of %s failed verification: %d files of %d bytes, want %d of %d", g.Name, files, size, sourceFiles, total)
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err = os.Rename(staged, target); err != nil {
+		return err
+	}
+	if keptSize >= 0 {
+		if err = renameVersions(stagedVersions, targetVersions); err != nil {
+			os.RemoveAll(target)
+			return err
+		}
+	}
+
+	if err = c.switchRecord(library, g, moved); err != nil {
+		if keptSize >= 0 {
+			os.RemoveAll(targetVersions)
+		}
+		os.RemoveAll(target)
+		return err
+	}
+
+	os.RemoveAll(record.InstallPath)
+
+	return c.removeMoveSource(source, g)
+}
+
+// treeStats counts the regular files beneath path and their total size.
+func treeStats(path string) (int, int64, error) {
+	var files int
+	var size int64
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+
+		return nil
+	})
+
+	return files, size, err
+}
+
+// renameVersions moves a kept versions dir into place, replacing whatever
+// an earlier install left in the target library.
+func renameVersions(src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+// switchRecord points the install database at the moved copy. The database
+// write is the point at which the move takes effect, so it comes last and
+// everything before it is undone if it fails.
+func (c *CoreAdapter) switchRecord(library string, g requests.Game, moved installdb.Record) error {
+	recordPath := installRecordPath(library, g)
+	if err := installdb.WriteRecord(recordPath, moved); err != nil {
+		return err
+	}
+
+	old := c.config.Game(g.ID)
+	gc := c.config.Game(g.ID)
+	gc.Library = library
+	if def, err := c.defaultLibrary(); err == nil && def == library {
+		gc.Library = ""
+	}
+
+	if err := c.config.SetGame(g.ID, gc); err != nil {
+		os.Remove(recordPath)
+		return err
+	}
+
+	if err := c.db.Put(moved); err != nil {
+		c.config.SetGame(g.ID, old)
+		os.Remove(recordPath)
+		return err
+	}
+
+	return nil
+}
+
+// removeMoveSource clears what is left of a game in the library it was moved
+// out of.
+func (c *CoreAdapter) removeMoveSource(source string, g requests.Game) error {
+	var errs []error
+
+	if err := os.Remove(installRecordPath(source, g)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	if err := os.RemoveAll(versionsDir(source, g)); err != nil {
+		errs = append(errs, err)
+	}
+	removeStaleStaging(source, g)
+
+	return errors.Join(errs...)
+}
+
+// copyTree copies src to dst, which must not exist yet, keeping file modes
+// and symlinks. File contents are synced before returning so the source can
+// be removed safely.
+func copyTree(ctx context.Context, src, dst string, progress io.Writer) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(ctx, p, target, info.Mode().Perm(), progress)
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(ctx context.Context, src, dst string, perm fs.FileMode, progress io.Writer) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(io.MultiWriter(out, progress), ctxReader{ctx: ctx, r: in}); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
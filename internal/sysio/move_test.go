@@ -0,0 +1,111 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+func TestMoveGameKeepsVersions(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	source := filepath.Join(root, "a")
+	target := filepath.Join(root, "b")
+	g := requests.Game{ID: 1, Name: "Keizai"}
+	layout := LinuxLayout{}
+
+	cfg, err := config.Load(filepath.Join(root, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := installdb.Open(filepath.Join(root, "installs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCoreAdapter(layout, func() (string, error) { return home, nil }, cfg, db)
+
+	live := layout.BundleDir(source, g)
+	writeFile(t, filepath.Join(live, "Keizai"), minimalELF())
+	record := installdb.Record{GameID: g.ID, Library: source, InstallPath: live, Version: "v2.0.0", Executable: "Keizai"}
+	if err = db.Put(record); err != nil {
+		t.Fatal(err)
+	}
+
+	kept := filepath.Join(versionsDir(source, g), versionDirName("v1.0.0"))
+	writeFile(t, filepath.Join(layout.BundleDir(kept, g), "Keizai"), minimalELF())
+	keptRecord := installdb.Record{GameID: g.ID, Library: source, Version: "v1.0.0", InstalledAt: time.Now()}
+	if err = os.MkdirAll(filepath.Dir(installRecordPath(kept, g)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = installdb.WriteRecord(installRecordPath(kept, g), keptRecord); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = cfg.AddLibrary(target); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.MoveGame(context.Background(), g, target, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, ok := db.Get(g.ID)
+	if !ok || moved.Library != target || moved.InstallPath != layout.BundleDir(target, g) {
+		t.Fatalf("record after move = %+v", moved)
+	}
+	if lib := cfg.Game(g.ID).Library; lib != target {
+		t.Errorf("config library = %q, want %q", lib, target)
+	}
+	if _, err = os.Stat(versionsDir(source, g)); !os.IsNotExist(err) {
+		t.Errorf("versions left in source library: %v", err)
+	}
+
+	versions, err := c.ListVersions(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Version != "v1.0.0" {
+		t.Errorf("ListVersions after move = %+v, want v1.0.0", versions)
+	}
+}
+
+func TestMoveGameUnknownLibrary(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	g := requests.Game{ID: 1, Name: "Keizai"}
+	layout := LinuxLayout{}
+
+	cfg, err := config.Load(filepath.Join(root, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := installdb.Open(filepath.Join(root, "installs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCoreAdapter(layout, func() (string, error) { return home, nil }, cfg, db)
+
+	source := layout.InstallDir(home)
+	live := layout.BundleDir(source, g)
+	writeFile(t, filepath.Join(live, "Keizai"), minimalELF())
+	if err = db.Put(installdb.Record{GameID: g.ID, Library: source, InstallPath: live, Executable: "Keizai"}); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(root, "elsewhere")
+	if err = c.MoveGame(context.Background(), g, target, nil); !errors.Is(err, ErrUnknownLibrary) {
+		t.Fatalf("MoveGame = %v, want %v", err, ErrUnknownLibrary)
+	}
+	if _, err = os.Stat(filepath.Join(live, "Keizai")); err != nil {
+		t.Errorf("game was touched: %v", err)
+	}
+	if _, err = os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("target was created: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package sysio
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	PHASE_DOWNLOADING Phase = iota
+	PHASE_VERIFYING
+	PHASE_EXTRACTING
+	PHASE_INDEXING
+	PHASE_COPYING
+)
+
+const (
+	PROGRESS_REPORT_INTERVAL = 100 * time.Millisecond
+)
+
+type Phase int
+
+func (p Phase) String() string {
+	switch p {
+	case PHASE_DOWNLOADING:
+		return "Downloading"
+	case PHASE_VERIFYING:
+		return "Verifying"
+	case PHASE_EXTRACTING:
+		return "Extracting"
+	case PHASE_INDEXING:
+		return "Indexing"
+	case PHASE_COPYING:
+		return "Copying"
+	default:
+		return "Working"
+	}
+}
+
+// Progress is a snapshot of a download or install step. Total is zero when
+// the size of the step is unknown.
+type Progress struct {
+	Phase Phase
+	Done  int64
+	Total int64
+	// Rate is in bytes per second.
+	Rate float64
+	ETA  time.Duration
+}
+
+// ProgressFunc receives progress reports. It is called from the goroutine
+// doing the work, so implementations must not block for long.
+type ProgressFunc func(Progress)
+
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+
+	return float64(p.Done) / float64(p.Total)
+}
+
+func (p Progress) String() string {
+	if p.Total <= 0 {
+		return fmt.Sprintf("%s %s", p.Phase, FormatBytes(p.Done))
+	}
+
+	s := fmt.Sprintf("%s %s / %s", p.Phase, FormatBytes(p.Done), FormatBytes(p.Total))
+	if p.Rate > 0 {
+		s += fmt.Sprintf(" · %s/s · %s left", FormatBytes(int64(p.Rate)), p.ETA.Round(time.Second))
+	}
+
+	return s
+}
+
+// FormatBytes renders a byte count with binary units, e.g. "1.5 GiB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressTracker is an io.Writer that counts bytes flowing through a step
+// and reports them at most every PROGRESS_REPORT_INTERVAL.
+type progressTracker struct {
+	report    ProgressFunc
+	phase     Phase
+	done      int64
+	total     int64
+	start     time.Time
+	startDone int64
+	last      time.Time
+}
+
+func newProgressTracker(report ProgressFunc, phase Phase, done, total int64) *progressTracker {
+	t := &progressTracker{
+		report:    report,
+		phase:     phase,
+		done:      done,
+		total:     total,
+		start:     time.Now(),
+		startDone: done,
+	}
+	t.flush()
+
+	return t
+}
+
+func (t *progressTracker) Write(b []byte) (int, error) {
+	t.set(t.done + int64(len(b)))
+
+	return len(b), nil
+}
+
+func (t *progressTracker) set(done int64) {
+	t.done = done
+
+	if time.Since(t.last) >= PROGRESS_REPORT_INTERVAL {
+		t.flush()
+	}
+}
+
+func (t *progressTracker) flush() {
+	if t.report == nil {
+		return
+	}
+
+	t.last = time.Now()
+
+	p := Progress{
+		Phase: t.phase,
+		Done:  t.done,
+		Total: t.total,
+	}
+
+	// Rate only counts bytes moved in this session so resumed downloads do
+	// not report an inflated speed.
+	if elapsed := t.last.Sub(t.start).Seconds(); elapsed > 0 {
+		p.Rate = float64(t.done-t.startDone) / elapsed
+	}
+	if p.Rate > 0 && t.total > t.done {
+		p.ETA = time.Duration(float64(t.total-t.done) / p.Rate * float64(time.Second))
+	}
+
+	t.report(p)
+}
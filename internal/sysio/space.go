@@ -0,0 +1,114 @@
+package sysio
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	// EXTRACT_SIZE_RATIO estimates how much larger an archive gets once
+	// extracted when the provider does not declare an install size.
+	EXTRACT_SIZE_RATIO = 3
+)
+
+// SpaceError reports a volume without room for a download, install or
+// move.
+type SpaceError struct {
+	Path   string
+	Needed int64
+	Free   int64
+}
+
+func (e *SpaceError) Error() string {
+	return fmt.Sprintf("%s on %s: needs %s, %s free", ErrNotEnoughSpace, e.Path, FormatBytes(e.Needed), FormatBytes(e.Free))
+}
+
+func (e *SpaceError) Unwrap() error {
+	return ErrNotEnoughSpace
+}
+
+// CheckSpace estimates the space needed to download and install a release
+// of g and returns a *SpaceError if the download cache or the target
+// library is short of it. An empty tag checks the release the game's
+// channel selects.
+func (c *CoreAdapter) CheckSpace(ctx context.Context, p provider.ReleaseProvider, g requests.Game, tag string) error {
+	var release *provider.Release
+	var err error
+
+	if tag != "" {
+		release, err = findRelease(ctx, p, g, tag)
+	} else {
+		release, err = c.selectRelease(ctx, p, g)
+	}
+	if err != nil {
+		return err
+	}
+
+	asset, err := findPlatformAsset(release)
+	if err != nil {
+		return err
+	}
+
+	return c.checkSpace(g, asset)
+}
+
+func (c *CoreAdapter) checkSpace(g requests.Game, asset *provider.Asset) error {
+	library, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return err
+	}
+
+	cache, err := assetCachePath(g, asset)
+	if err != nil {
+		return err
+	}
+
+	download := remainingDownload(cache, asset)
+
+	install := asset.InstallSize
+	if install <= 0 {
+		install = asset.Size * EXTRACT_SIZE_RATIO
+	}
+
+	if sameVolume(cache, library) {
+		return requireSpace(library, download+install)
+	}
+
+	if err = requireSpace(cache, download); err != nil {
+		return err
+	}
+
+	return requireSpace(library, install)
+}
+
+// remainingDownload is how many bytes of asset are not at finalPath yet.
+func remainingDownload(finalPath string, asset *provider.Asset) int64 {
+	if info, err := os.Stat(finalPath); err == nil && info.Size() == asset.Size {
+		return 0
+	}
+	if info, err := os.Stat(finalPath + PART_FILE_EXT); err == nil && info.Size() <= asset.Size {
+		return asset.Size - info.Size()
+	}
+
+	return asset.Size
+}
+
+// requireSpace fails when path's volume has less than needed bytes free.
+// Volumes whose free space cannot be read are given the benefit of the
+// doubt.
+func requireSpace(path string, needed int64) error {
+	if needed <= 0 {
+		return nil
+	}
+
+	free, err := freeSpace(path)
+	if err != nil || free >= needed {
+		return nil
+	}
+
+	return &SpaceError{Path: path, Needed: needed, Free: free}
+}
@@ -0,0 +1,209 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/DillonEnge/keizai-launcher/internal/archive"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	STAGING_DIR_NAME  = "staging"
+	PREVIOUS_DIR_NAME = "previous"
+)
+
+var (
+	ErrInvalidInstall = errors.New("staged install failed validation")
+)
+
+// stagingDir is where installs are unpacked before they replace the live
+// copy. It lives inside the install dir so the final swap is a rename on a
+// single volume.
+func stagingDir(installDir string) string {
+	return filepath.Join(installDir, INSTALL_RECORD_DIR, STAGING_DIR_NAME)
+}
+
+// stageInstall extracts an archive into a fresh staging root and checks that
+// the result is launchable. The caller owns the returned directory.
+func (c *CoreAdapter) stageInstall(ctx context.Context, archivePath, installDir string, g requests.Game, record *installdb.Record, report ProgressFunc) (string, error) {
+	removeStaleStaging(installDir, g)
+
+	staging, err := newStaging(installDir, g)
+	if err != nil {
+		return "", err
+	}
+
+	extractPath := c.layout.ExtractDir(staging, g)
+
+	if err = os.MkdirAll(extractPath, 0755); err != nil {
+		return staging, err
+	}
+
+	tracker := newProgressTracker(report, PHASE_EXTRACTING, 0, 0)
+
+	err = archive.Extract(ctx, archivePath, extractPath, archive.DefaultLimits, func(done, total int64) {
+		tracker.total = total
+		tracker.set(done)
+	})
+	if err != nil {
+		return staging, err
+	}
+	tracker.flush()
+
+	exePath, err := c.layout.ExecutablePath(staging, g)
+	if err != nil {
+		return staging, fmt.Errorf("%w: %w", ErrInvalidInstall, err)
+	}
+
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return staging, fmt.Errorf("%w: %w", ErrInvalidInstall, err)
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return staging, fmt.Errorf("%w: %s is not an executable file", ErrInvalidInstall, filepath.Base(exePath))
+	}
+
+	bundle := c.layout.BundleDir(staging, g)
+
+	rel, err := filepath.Rel(bundle, exePath)
+	if err != nil {
+		return staging, err
+	}
+	record.Executable = filepath.ToSlash(rel)
+	record.InstallPath = bundle
+
+	record.Files, err = hashFiles(ctx, bundle, report)
+	if err != nil {
+		return staging, err
+	}
+
+	// The record goes into the staging root too so layouts that read their
+	// version from it can be validated the same way as the others.
+	if err = os.MkdirAll(filepath.Join(staging, INSTALL_RECORD_DIR), 0755); err != nil {
+		return staging, err
+	}
+	if err = installdb.WriteRecord(installRecordPath(staging, g), *record); err != nil {
+		return staging, err
+	}
+
+	if _, err = c.layout.VersionSource(staging, g).ReadVersion(); err != nil {
+		return staging, fmt.Errorf("%w: failed to read version: %w", ErrInvalidInstall, err)
+	}
+
+	return staging, nil
+}
+
+// commitInstall swaps a staged bundle into place. The live bundle is moved
+// aside first and put back if anything after that fails, so a failed update
+// leaves the previous version untouched. Once the swap has succeeded the
+// previous version is kept for rollback.
+func (c *CoreAdapter) commitInstall(installDir, staging string, g requests.Game, record installdb.Record) error {
+	c.swap.Lock()
+	defer c.swap.Unlock()
+
+	return c.commitInstallLocked(installDir, staging, g, record)
+}
+
+// commitInstallLocked is commitInstall for callers already holding c.swap.
+func (c *CoreAdapter) commitInstallLocked(installDir, staging string, g requests.Game, record installdb.Record) error {
+	live := c.layout.BundleDir(installDir, g)
+	staged := c.layout.BundleDir(staging, g)
+	previous := filepath.Join(staging, PREVIOUS_DIR_NAME)
+
+	if err := os.MkdirAll(filepath.Dir(live), 0755); err != nil {
+		return err
+	}
+
+	previousRecord, previousErr := c.currentRecord(installDir, g)
+
+	hadPrevious := true
+	if err := os.Rename(live, previous); errors.Is(err, fs.ErrNotExist) {
+		hadPrevious = false
+	} else if err != nil {
+		return fmt.Errorf("failed to move current install aside: %w", err)
+	}
+
+	// The staged bundle is moved back rather than deleted, since for a
+	// rollback it is the only copy of the kept version. Only if that fails
+	// is it removed so the previous version can go back in place.
+	swapped := false
+	rollback := func(cause error) error {
+		if swapped {
+			if err := os.Rename(live, staged); err != nil {
+				os.RemoveAll(live)
+			}
+		}
+		if hadPrevious {
+			if err := os.Rename(previous, live); err != nil {
+				return fmt.Errorf("%w (rollback failed: %w)", cause, err)
+			}
+		}
+		return cause
+	}
+
+	if err := os.Rename(staged, live); err != nil {
+		return rollback(err)
+	}
+	swapped = true
+
+	if err := os.MkdirAll(filepath.Join(installDir, INSTALL_RECORD_DIR), 0755); err != nil {
+		return rollback(err)
+	}
+
+	// Rolled back versions may have been kept in another library before
+	// the game was moved.
+	record.Library = installDir
+	record.InstallPath = live
+
+	recordPath := installRecordPath(installDir, g)
+	oldRecord, oldRecordErr := installdb.ReadRecord(recordPath)
+
+	if err := installdb.WriteRecord(recordPath, record); err != nil {
+		return rollback(err)
+	}
+
+	if err := c.db.Put(record); err != nil {
+		if oldRecordErr == nil {
+			installdb.WriteRecord(recordPath, *oldRecord)
+		} else {
+			os.Remove(recordPath)
+		}
+		return rollback(err)
+	}
+
+	// The new version is live either way, so failing to keep the old one
+	// only costs the option to roll back to it.
+	if hadPrevious && previousErr == nil {
+		c.keepVersion(installDir, previous, g, *previousRecord)
+	}
+
+	return nil
+}
+
+// gameStagingDir holds the staging roots of g alone, keyed by its ID so no
+// other game's roots can be mistaken for its own.
+func gameStagingDir(installDir string, g requests.Game) string {
+	return filepath.Join(stagingDir(installDir), strconv.Itoa(g.ID))
+}
+
+// newStaging creates a fresh staging root for g. The caller owns it.
+func newStaging(installDir string, g requests.Game) (string, error) {
+	if err := os.MkdirAll(gameStagingDir(installDir, g), 0755); err != nil {
+		return "", err
+	}
+
+	return os.MkdirTemp(gameStagingDir(installDir, g), "")
+}
+
+// removeStaleStaging clears staging roots left behind by installs of g that
+// were interrupted before they could clean up.
+func removeStaleStaging(installDir string, g requests.Game) {
+	os.RemoveAll(gameStagingDir(installDir, g))
+}
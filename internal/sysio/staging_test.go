@@ -0,0 +1,36 @@
+package sysio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+func TestRemoveStaleStagingKeepsOtherGames(t *testing.T) {
+	library := t.TempDir()
+	foo := requests.Game{ID: 1, Name: "foo"}
+	fooBar := requests.Game{ID: 2, Name: "foo-bar"}
+
+	stale, err := newStaging(library, foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newStaging(library, fooBar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(stale) == filepath.Dir(other) {
+		t.Fatalf("games share staging dir %s", filepath.Dir(stale))
+	}
+
+	removeStaleStaging(library, foo)
+
+	if _, err = os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale staging root was kept: %v", err)
+	}
+	if _, err = os.Stat(other); err != nil {
+		t.Errorf("other game's staging root was removed: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package sysio
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+type UninstallOptions struct {
+	// RemoveUserData also deletes the saves and settings the game keeps
+	// outside its install directory.
+	RemoveUserData bool
+}
+
+// Uninstall removes g along with its install record, kept versions,
+// shortcuts and cached downloads. Everything that can be removed is, and the
+// errors are reported together.
+func (c *CoreAdapter) Uninstall(g requests.Game, opts UninstallOptions) error {
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return err
+	}
+
+	home, err := c.GetHomeDirPath()
+	if err != nil {
+		return err
+	}
+
+	c.swap.Lock()
+	defer c.swap.Unlock()
+
+	bundle := c.layout.BundleDir(path, g)
+	if record, ok := c.db.Get(g.ID); ok {
+		bundle = record.InstallPath
+	}
+
+	if err = os.RemoveAll(bundle); err != nil {
+		return err
+	}
+
+	if err = c.db.Delete(g.ID); err != nil {
+		return err
+	}
+
+	var errs []error
+
+	if err = os.Remove(installRecordPath(path, g)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	if err = os.RemoveAll(versionsDir(path, g)); err != nil {
+		errs = append(errs, err)
+	}
+	removeStaleStaging(path, g)
+
+	for _, p := range c.layout.ShortcutPaths(home, g) {
+		if err = removeShortcut(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err = removeCachedAssets(g); err != nil {
+		errs = append(errs, err)
+	}
+
+	if opts.RemoveUserData {
+		dirs, err := c.UserDataDirs(g)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		for _, dir := range dirs {
+			if err = os.RemoveAll(dir); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// UserDataDirs lists the saves and settings dirs of g that Uninstall deletes
+// when asked to. Dirs that hold or lie inside the launcher's own config,
+// data or cache, or any library, are left out whatever the registry says.
+func (c *CoreAdapter) UserDataDirs(g requests.Game) ([]string, error) {
+	home, err := c.GetHomeDirPath()
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := c.protectedDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, dir := range c.layout.UserDataDirs(home, g) {
+		if !overlapsAny(dir, protected) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs, nil
+}
+
+// protectedDirs are the dirs no game's data dir may overlap.
+func (c *CoreAdapter) protectedDirs() ([]string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []string{configDir, dataDir, filepath.Join(cacheDir, CACHE_DIR_NAME)}
+
+	libraries, err := c.Libraries()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range libraries {
+		dirs = append(dirs, l.Path)
+	}
+
+	return dirs, nil
+}
+
+// overlapsAny reports whether dir is, holds or lies inside any of dirs.
+func overlapsAny(dir string, dirs []string) bool {
+	for _, d := range dirs {
+		if pathContains(dir, d) || pathContains(d, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeShortcut deletes a shortcut file or link. Directories are left
+// alone since a real app may sit where a link was expected.
+func removeShortcut(p string) error {
+	info, err := os.Lstat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	return os.Remove(p)
+}
+
+// pathContains reports whether child is parent or lies beneath it.
+func pathContains(parent, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
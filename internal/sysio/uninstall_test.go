@@ -0,0 +1,56 @@
+package sysio
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/DillonEnge/keizai-launcher/internal/config"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+func TestUserDataDirsSkipsLauncherDirs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses the XDG dirs")
+	}
+
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+
+	cfg, err := config.Load(filepath.Join(root, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := installdb.Open(filepath.Join(root, "installs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := LinuxLayout{DataHome: filepath.Join(home, ".local", "share"), ConfigHome: filepath.Join(home, ".config")}
+	c := NewCoreAdapter(layout, func() (string, error) { return home, nil }, cfg, db)
+
+	// The launcher's own dirs share its name, and the default library sits
+	// in the data home.
+	g := requests.Game{ID: 1, Name: "Keizai", DataDirs: []string{config.CONFIG_DIR_NAME, filepath.Base(layout.InstallDir(home)), "Keizai"}}
+
+	dirs, err := c.UserDataDirs(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(home, ".local", "share", "Keizai"),
+		filepath.Join(home, ".config", "Keizai"),
+	}
+	if len(dirs) != len(want) {
+		t.Fatalf("UserDataDirs = %q, want %q", dirs, want)
+	}
+	for i := range want {
+		if dirs[i] != want[i] {
+			t.Errorf("UserDataDirs = %q, want %q", dirs, want)
+		}
+	}
+}
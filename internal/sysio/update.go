@@ -0,0 +1,93 @@
+package sysio
+
+import (
+	"fmt"
+
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/semver"
+)
+
+const (
+	RELEASE_EQUAL Comparison = iota
+	RELEASE_NEWER
+	RELEASE_OLDER
+)
+
+// Comparison describes the available release relative to the installed one.
+type Comparison int
+
+type UpdateStatus struct {
+	Installed  semver.Version
+	Latest     semver.Version
+	Comparison Comparison
+	// Pinned games move to the pinned release even when it is older.
+	Pinned bool
+	// Unverified installs matched neither a published checksum nor a
+	// publisher signature.
+	Unverified bool
+}
+
+func (u UpdateStatus) UpdateAvailable() bool {
+	if u.Pinned {
+		return u.Comparison != RELEASE_EQUAL
+	}
+
+	return u.Comparison == RELEASE_NEWER
+}
+
+func (u UpdateStatus) String() string {
+	s := u.Installed.String()
+	if u.Comparison != RELEASE_EQUAL {
+		s = fmt.Sprintf("%s → %s", u.Installed, u.Latest)
+	}
+
+	if u.Unverified {
+		s += " · unverified"
+	}
+
+	return s
+}
+
+func newUpdateStatus(installed, latest semver.Version) *UpdateStatus {
+	status := &UpdateStatus{
+		Installed: installed,
+		Latest:    latest,
+	}
+
+	switch latest.Compare(installed) {
+	case 1:
+		status.Comparison = RELEASE_NEWER
+	case -1:
+		status.Comparison = RELEASE_OLDER
+	default:
+		status.Comparison = RELEASE_EQUAL
+	}
+
+	return status
+}
+
+// releaseVersion prefers the release tag and only falls back to the
+// human-readable release name when the tag is not a semantic version.
+func releaseVersion(r *provider.Release) (semver.Version, error) {
+	v, err := semver.Parse(r.Tag)
+	if err == nil {
+		return v, nil
+	}
+
+	if v, nameErr := semver.Parse(r.Name); nameErr == nil {
+		return v, nil
+	}
+
+	return semver.Version{}, fmt.Errorf("failed to parse release version: %w", err)
+}
+
+// releaseVersionString is what gets recorded for an installed release.
+func releaseVersionString(r *provider.Release) string {
+	if v, err := releaseVersion(r); err == nil {
+		return v.String()
+	}
+	if r.Tag != "" {
+		return r.Tag
+	}
+	return r.Name
+}
@@ -0,0 +1,255 @@
+package sysio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/DillonEnge/keizai-launcher/internal/archive"
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/provider"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	FILE_MISSING FileProblem = iota
+	FILE_MODIFIED
+)
+
+var (
+	ErrNotRecorded = errors.New("game has no install record")
+	ErrNotRepaired = errors.New("files are still damaged after repair")
+)
+
+type FileProblem int
+
+func (p FileProblem) String() string {
+	switch p {
+	case FILE_MISSING:
+		return "missing"
+	case FILE_MODIFIED:
+		return "modified"
+	default:
+		return "damaged"
+	}
+}
+
+type DamagedFile struct {
+	Path    string
+	Problem FileProblem
+}
+
+// VerifyReport is the result of checking an install against its record.
+type VerifyReport struct {
+	Checked int
+	Damaged []DamagedFile
+}
+
+func (r VerifyReport) OK() bool {
+	return len(r.Damaged) == 0
+}
+
+func (r VerifyReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("All %d files OK", r.Checked)
+	}
+
+	var missing, modified int
+	for _, f := range r.Damaged {
+		if f.Problem == FILE_MISSING {
+			missing++
+		} else {
+			modified++
+		}
+	}
+
+	return fmt.Sprintf("%d of %d files damaged (%d missing, %d modified)", len(r.Damaged), r.Checked, missing, modified)
+}
+
+// VerifyGame re-hashes the installed files of g against its install record.
+func (c *CoreAdapter) VerifyGame(ctx context.Context, g requests.Game, report ProgressFunc) (*VerifyReport, error) {
+	record, ok := c.db.Get(g.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, g.Name)
+	}
+
+	return verifyFiles(ctx, record, report)
+}
+
+// RepairGame downloads the installed release of g again and restores only
+// the files that fail verification.
+func (c *CoreAdapter) RepairGame(ctx context.Context, p provider.ReleaseProvider, g requests.Game, report ProgressFunc) (*VerifyReport, error) {
+	record, ok := c.db.Get(g.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, g.Name)
+	}
+
+	result, err := verifyFiles(ctx, record, report)
+	if err != nil || result.OK() {
+		return result, err
+	}
+
+	release, err := findRelease(ctx, p, g, record.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var asset *provider.Asset
+	for i, a := range release.Assets {
+		if a.Name == record.AssetName {
+			asset = &release.Assets[i]
+		}
+	}
+	if asset == nil {
+		return nil, fmt.Errorf("release %s no longer has asset %s", record.Tag, record.AssetName)
+	}
+
+	if err = c.checkSpace(g, asset); err != nil {
+		return nil, err
+	}
+
+	c.cache.RLock()
+	defer c.cache.RUnlock()
+
+	archivePath, err := downloadAsset(ctx, p, g, asset, report)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archivePath)
+
+	sum, err := fileSHA256(ctx, archivePath, report)
+	if err != nil {
+		return nil, err
+	}
+	if sum != record.ArchiveSHA256 {
+		return nil, fmt.Errorf("%w: %s has sha256 %s, install was made from %s", ErrChecksumMismatch, asset.Name, sum, record.ArchiveSHA256)
+	}
+
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	c.swap.Lock()
+	defer c.swap.Unlock()
+
+	staging, err := newStaging(path, g)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	extractPath := c.layout.ExtractDir(staging, g)
+	if err = os.MkdirAll(extractPath, 0755); err != nil {
+		return nil, err
+	}
+
+	tracker := newProgressTracker(report, PHASE_EXTRACTING, 0, 0)
+
+	err = archive.Extract(ctx, archivePath, extractPath, archive.DefaultLimits, func(done, total int64) {
+		tracker.total = total
+		tracker.set(done)
+	})
+	if err != nil {
+		return nil, err
+	}
+	tracker.flush()
+
+	hashes := make(map[string]string, len(record.Files))
+	for _, f := range record.Files {
+		hashes[f.Path] = f.SHA256
+	}
+
+	staged := c.layout.BundleDir(staging, g)
+	for _, f := range result.Damaged {
+		if err = restoreFile(ctx, staged, record.InstallPath, f.Path, hashes[f.Path]); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+	}
+
+	result, err = verifyFiles(ctx, record, report)
+	if err != nil {
+		return nil, err
+	}
+	if !result.OK() {
+		return result, fmt.Errorf("%w: %s", ErrNotRepaired, result)
+	}
+
+	return result, nil
+}
+
+// verifyFiles checks every file in record. Files whose size already differs
+// are reported without being hashed.
+func verifyFiles(ctx context.Context, record installdb.Record, report ProgressFunc) (*VerifyReport, error) {
+	var total int64
+	for _, f := range record.Files {
+		total += f.Size
+	}
+
+	tracker := newProgressTracker(report, PHASE_VERIFYING, 0, total)
+	result := &VerifyReport{Checked: len(record.Files)}
+
+	for _, f := range record.Files {
+		p := filepath.Join(record.InstallPath, filepath.FromSlash(f.Path))
+
+		info, err := os.Lstat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			result.Damaged = append(result.Damaged, DamagedFile{Path: f.Path, Problem: FILE_MISSING})
+			tracker.set(tracker.done + f.Size)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.Mode().IsRegular() || info.Size() != f.Size {
+			result.Damaged = append(result.Damaged, DamagedFile{Path: f.Path, Problem: FILE_MODIFIED})
+			tracker.set(tracker.done + f.Size)
+			continue
+		}
+
+		sum, err := hashFile(ctx, p, tracker)
+		if err != nil {
+			return nil, err
+		}
+		if sum != f.SHA256 {
+			result.Damaged = append(result.Damaged, DamagedFile{Path: f.Path, Problem: FILE_MODIFIED})
+		}
+	}
+	tracker.flush()
+
+	return result, nil
+}
+
+// restoreFile copies rel from the freshly extracted bundle over the damaged
+// one, checking it against the recorded hash before it replaces anything.
+func restoreFile(ctx context.Context, srcRoot, dstRoot, rel, sha256 string) error {
+	src := filepath.Join(srcRoot, filepath.FromSlash(rel))
+	dst := filepath.Join(dstRoot, filepath.FromSlash(rel))
+
+	sum, err := hashFile(ctx, src, io.Discard)
+	if err != nil {
+		return err
+	}
+	if sum != sha256 {
+		return fmt.Errorf("%w: release copy has sha256 %s, recorded %s", ErrChecksumMismatch, sum, sha256)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// Whatever is in the way may be a directory or a link, not just a
+	// damaged file.
+	if err = os.RemoveAll(dst); err != nil {
+		return err
+	}
+
+	// The staging dir is on the same volume, so this also keeps the mode
+	// the archive gave the file.
+	return os.Rename(src, dst)
+}
@@ -0,0 +1,261 @@
+package sysio
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DillonEnge/keizai-launcher/internal/installdb"
+	"github.com/DillonEnge/keizai-launcher/internal/requests"
+)
+
+const (
+	VERSIONS_DIR_NAME = "versions"
+)
+
+var (
+	ErrVersionNotKept = errors.New("version is not kept")
+)
+
+// InstalledVersion is a previous version of a game kept on disk for
+// rollback.
+type InstalledVersion struct {
+	Version     string
+	InstalledAt time.Time
+	Size        int64
+	dir         string
+}
+
+// versionsDir holds one directory per kept version of g. Each is laid out
+// like an install dir of its own, with the bundle and its install record.
+func versionsDir(installDir string, g requests.Game) string {
+	return filepath.Join(installDir, INSTALL_RECORD_DIR, VERSIONS_DIR_NAME, strings.ToLower(g.Name))
+}
+
+// ListVersions returns the kept versions of g, newest install first.
+func (c *CoreAdapter) ListVersions(g requests.Game) ([]InstalledVersion, error) {
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.listVersions(path, g)
+}
+
+// RollbackVersion swaps a kept version of g back into place. The version it
+// replaces is kept in turn.
+func (c *CoreAdapter) RollbackVersion(g requests.Game, version string) error {
+	path, err := c.GetInstallDirPath(g)
+	if err != nil {
+		return err
+	}
+
+	c.swap.Lock()
+	defer c.swap.Unlock()
+
+	versions, err := c.listVersions(path, g)
+	if err != nil {
+		return err
+	}
+
+	var kept *InstalledVersion
+	for i, v := range versions {
+		if v.Version == version {
+			kept = &versions[i]
+			break
+		}
+	}
+	if kept == nil {
+		return fmt.Errorf("%w: %s %s", ErrVersionNotKept, g.Name, version)
+	}
+
+	record, err := installdb.ReadRecord(installRecordPath(kept.dir, g))
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.layout.ExecutablePath(kept.dir, g); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInstall, err)
+	}
+
+	staging, err := newStaging(path, g)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	// Take the kept copy out of the versions dir first so keeping the
+	// current version cannot prune it.
+	restored := filepath.Join(staging, VERSIONS_DIR_NAME)
+	if err = os.Rename(kept.dir, restored); err != nil {
+		return err
+	}
+
+	if err = c.commitInstallLocked(path, restored, g, *record); err != nil {
+		os.Rename(restored, kept.dir)
+		return err
+	}
+
+	return nil
+}
+
+func (c *CoreAdapter) listVersions(installDir string, g requests.Game) ([]InstalledVersion, error) {
+	entries, err := os.ReadDir(versionsDir(installDir, g))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]InstalledVersion, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(versionsDir(installDir, g), e.Name())
+
+		record, err := installdb.ReadRecord(installRecordPath(dir, g))
+		if err != nil {
+			continue
+		}
+
+		size, err := dirSize(c.layout.BundleDir(dir, g))
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, InstalledVersion{
+			Version:     record.Version,
+			InstalledAt: record.InstalledAt,
+			Size:        size,
+			dir:         dir,
+		})
+	}
+
+	sort.Slice(versions, func(a, b int) bool {
+		return versions[a].InstalledAt.After(versions[b].InstalledAt)
+	})
+
+	return versions, nil
+}
+
+// keepVersion moves a bundle that was just replaced into the versions dir
+// and prunes the kept versions down to the configured count and size.
+func (c *CoreAdapter) keepVersion(installDir, bundle string, g requests.Game, record installdb.Record) error {
+	if c.config.GetKeepVersions() == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(versionsDir(installDir, g), versionDirName(record.Version))
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, INSTALL_RECORD_DIR), 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.layout.BundleDir(dir, g)), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(bundle, c.layout.BundleDir(dir, g)); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	record.InstallPath = c.layout.BundleDir(dir, g)
+
+	if err := installdb.WriteRecord(installRecordPath(dir, g), record); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	return c.pruneVersions(installDir, g)
+}
+
+func (c *CoreAdapter) pruneVersions(installDir string, g requests.Game) error {
+	versions, err := c.listVersions(installDir, g)
+	if err != nil {
+		return err
+	}
+
+	keep := c.config.GetKeepVersions()
+	maxSize := c.config.GetMaxKeptVersionsSize()
+
+	var total int64
+	for i, v := range versions {
+		total += v.Size
+		if i < keep && (maxSize <= 0 || total <= maxSize) {
+			continue
+		}
+
+		if err := os.RemoveAll(v.dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// currentRecord describes the live install of g. Installs made before the
+// launcher kept records only have their version to go on.
+func (c *CoreAdapter) currentRecord(installDir string, g requests.Game) (*installdb.Record, error) {
+	if record, ok := c.db.Get(g.ID); ok {
+		return &record, nil
+	}
+	if record, err := installdb.ReadRecord(installRecordPath(installDir, g)); err == nil {
+		return record, nil
+	}
+
+	ver, err := c.layout.VersionSource(installDir, g).ReadVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return &installdb.Record{GameID: g.ID, Version: ver}, nil
+}
+
+func versionDirName(version string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, version)
+
+	if name == "" || name == "." || name == ".." {
+		return "unknown"
+	}
+
+	return name
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+
+		return nil
+	})
+
+	return size, err
+}
@@ -1,174 +1,71 @@
-//go:build windows
-
 package sysio
 
 import (
-	"context"
-	"fmt"
-	"io"
-	"io/fs"
-	"net/http"
-	"os"
-	"os/exec"
-	"runtime"
-	"slices"
+	"path/filepath"
 	"strings"
 
 	"github.com/DillonEnge/keizai-launcher/internal/requests"
-	"github.com/bi-zone/go-fileversion"
-	"github.com/google/go-github/v62/github"
-	"github.com/walle/targz"
 )
 
 const (
 	DEFAULT_INSTALL_DIR_WINDOWS = "\\Program Files\\Engehost"
+	START_MENU_DIR_WINDOWS      = "Microsoft\\Windows\\Start Menu\\Programs\\Engehost"
+	FILE_MANAGER_WINDOWS        = "explorer"
 )
 
-type WindowsAdapter struct{}
-
-var _ Adapter = (*WindowsAdapter)(nil)
+// WindowsLayout installs under Program Files. AppData mirrors %APPDATA%; when
+// it is not an absolute path there is nowhere to look for shortcuts or user
+// data.
+type WindowsLayout struct {
+	AppData string
+}
 
-func (w *WindowsAdapter) GetInstallDirPath() (string, error) {
-	p, err := w.GetHomeDirPath()
-	if err != nil {
-		return "", err
-	}
+var _ InstallLayout = WindowsLayout{}
 
-	return p + DEFAULT_INSTALL_DIR_WINDOWS, nil
+func (WindowsLayout) InstallDir(homeDir string) string {
+	return filepath.Join(homeDir, DEFAULT_INSTALL_DIR_WINDOWS)
 }
 
-func (w *WindowsAdapter) GetHomeDirPath() (string, error) {
-	return "C:", nil
+func (WindowsLayout) ExtractDir(installDir string, g requests.Game) string {
+	return installDir
 }
 
-func (w *WindowsAdapter) DownloadLatestRelease(client *github.Client, g requests.Game) (*string, error) {
-	release, _, err := client.Repositories.GetLatestRelease(context.Background(), g.RepoOwner, g.RepoName)
-	if err != nil {
-		return nil, err
-	}
-
-	var asset *github.ReleaseAsset
-	for _, v := range release.Assets {
-		if strings.Contains(*v.Name, fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)) {
-			asset = v
-		}
-	}
-
-	if asset == nil {
-		return nil, fmt.Errorf("failed to find asset with GOOS and GOARCH")
-	}
-
-	out, err := os.Create(*asset.Name)
-	if err != nil {
-		return nil, err
-	}
-	defer out.Close()
-
-	resp, err := http.Get(*asset.BrowserDownloadURL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return asset.Name, nil
+func (WindowsLayout) BundleDir(installDir string, g requests.Game) string {
+	return filepath.Join(installDir, strings.ToLower(g.Name))
 }
 
-func (w *WindowsAdapter) InstallLatestRelease(filePath *string, g requests.Game) error {
-	path, err := w.GetInstallDirPath()
-	if err != nil {
-		return err
-	}
-
-	err = targz.Extract(*filePath, path)
-	if err != nil {
-		return err
-	}
-
-	err = os.Chmod(path+"\\"+strings.ToLower(g.Name)+"\\"+g.Name+".exe", 0755)
-	if err != nil {
-		return err
-	}
-
-	err = os.Remove(*filePath)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (w WindowsLayout) ExecutablePath(installDir string, g requests.Game) (string, error) {
+	return filepath.Join(w.BundleDir(installDir, g), g.Name+".exe"), nil
 }
 
-func (w *WindowsAdapter) CheckForGame(g requests.Game) (bool, error) {
-	path, err := w.GetInstallDirPath()
-	if err != nil {
-		return false, err
-	}
-
-	dir, err := os.ReadDir(path)
-	if err != nil {
-		return false, err
-	}
-	if slices.ContainsFunc(dir, func(de fs.DirEntry) bool {
-		return de.Name() == strings.ToLower(g.Name)
-	}) {
-		return true, nil
-	}
-
-	return false, nil
+func (w WindowsLayout) VersionSource(installDir string, g requests.Game) VersionSource {
+	exePath, _ := w.ExecutablePath(installDir, g)
+	return FileVersion{Path: exePath}
 }
 
-func (w *WindowsAdapter) CheckLatest(client *github.Client, g requests.Game) (bool, error) {
-	path, err := w.GetInstallDirPath()
-	if err != nil {
-		return false, err
-	}
-
-	ver, err := w.GetVersion(path, g)
-	if err != nil {
-		return false, err
+func (w WindowsLayout) ShortcutPaths(homeDir string, g requests.Game) []string {
+	if !filepath.IsAbs(w.AppData) {
+		return nil
 	}
 
-	release, _, err := client.Repositories.GetLatestRelease(context.Background(), g.RepoOwner, g.RepoName)
-	if err != nil {
-		return false, err
-	}
-
-	if *release.Name != *ver {
-		return false, nil
-	}
-
-	return true, nil
+	return []string{filepath.Join(w.AppData, START_MENU_DIR_WINDOWS, g.Name+".lnk")}
 }
 
-func (w *WindowsAdapter) GetVersion(appPath string, g requests.Game) (*string, error) {
-	f, err := fileversion.New(appPath + "\\" + strings.ToLower(g.Name) + "\\" + g.Name + ".exe")
-	if err != nil {
-		return nil, err
+func (w WindowsLayout) UserDataDirs(homeDir string, g requests.Game) []string {
+	if !filepath.IsAbs(w.AppData) {
+		return nil
 	}
 
-	v, ok := strings.CutSuffix(f.FixedInfo().FileVersion.String(), ".0")
-	if !ok {
-		return nil, fmt.Errorf("failed to find trailing .0 in .exe fileversion")
+	var dirs []string
+	for _, name := range g.DataDirs {
+		if isDataDirName(name) {
+			dirs = append(dirs, filepath.Join(w.AppData, name))
+		}
 	}
 
-	ver := fmt.Sprintf("v%s", v)
-
-	return &ver, nil
-}
-
-func (w *WindowsAdapter) GetExecutableName(appPath string, g requests.Game) (*string, error) {
-	return &g.Name, nil
+	return dirs
 }
 
-func (w *WindowsAdapter) ExecuteGame(appPath string, g requests.Game) error {
-	cmd := exec.Command(appPath + "\\" + strings.ToLower(g.Name) + "\\" + g.Name + ".exe")
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	return nil
+func (w WindowsLayout) OpenCommand(path string) []string {
+	return []string{FILE_MANAGER_WINDOWS, path}
 }
@@ -26,6 +26,11 @@ const (
 	STATE_INSTALL ButtonState = iota
 	STATE_PLAY
 	STATE_UPDATE
+	// STATE_RUNNING is shown while the game is running and offers to stop
+	// it.
+	STATE_RUNNING
+	// STATE_CUSTOM keeps whatever text was set with SetText.
+	STATE_CUSTOM
 )
 
 type ButtonState int
@@ -85,6 +90,8 @@ func (b *Button) Update(g *game.Game) error {
 		b.text = "Install"
 	case STATE_UPDATE:
 		b.text = "Update"
+	case STATE_RUNNING:
+		b.text = "Stop"
 	default:
 	}
 
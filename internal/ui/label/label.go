@@ -48,3 +48,7 @@ func (l *Label) Draw(screen *ebiten.Image) {
 	l.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
 	l.txtRenderer.Draw(l.text, int(tx), int(ty))
 }
+
+func (l *Label) SetText(t string) {
+	l.text = t
+}
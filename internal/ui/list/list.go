@@ -0,0 +1,194 @@
+package list
+
+import (
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/tinne26/etxt"
+)
+
+type HandlerType int
+
+type Handler func(l *List, row, action int) error
+
+type Handlers map[HandlerType]Handler
+
+const (
+	HANDLER_ON_ACTION HandlerType = iota
+)
+
+const (
+	ACTION_WIDTH = 0.08
+)
+
+// Row is one line of the list. Fraction draws a progress strip under the
+// text when it is greater than zero.
+type Row struct {
+	Text     string
+	Fraction float64
+	Actions  []string
+}
+
+type List struct {
+	primaryColor color.Color
+	actionColor  color.Color
+	textColor    color.Color
+	textSize     int
+	x            float32
+	y            float32
+	width        float32
+	height       float32
+	rowHeight    float32
+	rows         []Row
+	hovered      [2]int
+	handlers     Handlers
+	txtRenderer  *etxt.Renderer
+}
+
+func NewList(
+	x, y, width, height, rowHeight float32, textSize int,
+	primaryColor, actionColor, textColor color.Color,
+	t *etxt.Renderer,
+) *List {
+	return &List{
+		x:            x,
+		y:            y,
+		width:        width,
+		height:       height,
+		rowHeight:    rowHeight,
+		textSize:     textSize,
+		primaryColor: primaryColor,
+		actionColor:  actionColor,
+		textColor:    textColor,
+		hovered:      [2]int{-1, -1},
+		handlers:     Handlers{},
+		txtRenderer:  t,
+	}
+}
+
+func (l *List) Update(g *game.Game) error {
+	lX, lY := g.LayoutF(1280, 720)
+	x, y := float32(lX), float32(lY)
+
+	mouseX, mouseY := ebiten.CursorPosition()
+	mX, mY := float32(mouseX), float32(mouseY)
+
+	l.hovered = [2]int{-1, -1}
+
+	for i, r := range l.rows {
+		for j := range r.Actions {
+			ax, ay, aw, ah := l.actionRect(i, j, x, y)
+			if mX > ax && mX < ax+aw && mY > ay && mY < ay+ah {
+				l.hovered = [2]int{i, j}
+
+				if inpututil.IsMouseButtonJustReleased(ebiten.MouseButton0) {
+					if f, ok := l.handlers[HANDLER_ON_ACTION]; ok {
+						return f(l, i, j)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *List) Draw(screen *ebiten.Image) {
+	sw, sh := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+
+	tx := l.x * sw
+	ty := l.y * sh
+	tw := l.width * sw
+	th := l.height * sh
+	trh := l.rowHeight * sh
+
+	vector.DrawFilledRect(
+		screen,
+		tx, ty,
+		tw, th,
+		l.primaryColor,
+		false,
+	)
+
+	l.txtRenderer.SetColor(l.textColor)
+	l.txtRenderer.SetTarget(screen)
+	l.txtRenderer.SetSizePx(l.textSize)
+
+	for i, r := range l.rows {
+		ry := ty + float32(i)*trh
+		if ry+trh > ty+th {
+			break
+		}
+
+		if r.Fraction > 0 {
+			vector.DrawFilledRect(
+				screen,
+				tx, ry+trh-4,
+				tw*float32(min(r.Fraction, 1)), 3,
+				l.actionColor,
+				false,
+			)
+		}
+
+		l.txtRenderer.SetAlign(etxt.YCenter, etxt.Left)
+		l.txtRenderer.Draw(r.Text, int(tx+10), int(ry+trh/2))
+
+		for j, a := range r.Actions {
+			ax, ay, aw, ah := l.actionRect(i, j, sw, sh)
+
+			c := l.actionColor
+			if l.hovered == [2]int{i, j} {
+				c = subRGBA(l.actionColor, 20)
+			}
+
+			vector.DrawFilledRect(
+				screen,
+				ax, ay,
+				aw, ah,
+				c,
+				false,
+			)
+
+			l.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+			l.txtRenderer.Draw(a, int(ax+aw/2), int(ay+ah/2))
+		}
+	}
+}
+
+// actionRect lays out a row's actions right to left from the list's edge.
+func (l *List) actionRect(row, action int, w, h float32) (float32, float32, float32, float32) {
+	aw := ACTION_WIDTH * w
+	ah := l.rowHeight * h * 0.7
+	n := len(l.rows[row].Actions)
+
+	ax := (l.x+l.width)*w - float32(n-action)*(aw+8)
+	ay := l.y*h + float32(row)*l.rowHeight*h + (l.rowHeight*h-ah)/2
+
+	return ax, ay, aw, ah
+}
+
+func (l *List) AddHandler(key HandlerType, h Handler) {
+	l.handlers[key] = h
+}
+
+func (l *List) SetRows(rows []Row) {
+	l.rows = rows
+}
+
+func subUInt8(n uint8, subn int) uint8 {
+	if int(n)-subn < 0 {
+		return 0
+	}
+
+	return n - uint8(subn)
+}
+
+func subRGBA(c color.Color, subn int) color.Color {
+	r, g, bl, _ := c.RGBA()
+	cr, cg, cbl := uint8(r), uint8(g), uint8(bl)
+
+	return color.RGBA{subUInt8(cr, subn), subUInt8(cg, subn), subUInt8(cbl, subn), 255}
+}
@@ -0,0 +1,131 @@
+package progressbar
+
+import (
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	REPORT_BUFFER_SIZE = 16
+)
+
+// Report is sent by whatever goroutine is doing the work.
+type Report struct {
+	Fraction float64
+	Text     string
+}
+
+type ProgressBar struct {
+	primaryColor color.Color
+	trackColor   color.Color
+	textColor    color.Color
+	textSize     int
+	x            float32
+	y            float32
+	width        float32
+	height       float32
+	txtRenderer  *etxt.Renderer
+	reports      chan Report
+	active       bool
+	fraction     float64
+	text         string
+}
+
+func NewProgressBar(
+	x, y, width, height float32, textSize int,
+	primaryColor, trackColor, textColor color.Color,
+	t *etxt.Renderer,
+) *ProgressBar {
+	return &ProgressBar{
+		x:            x,
+		y:            y,
+		width:        width,
+		height:       height,
+		textSize:     textSize,
+		primaryColor: primaryColor,
+		trackColor:   trackColor,
+		textColor:    textColor,
+		txtRenderer:  t,
+		reports:      make(chan Report, REPORT_BUFFER_SIZE),
+	}
+}
+
+// Send delivers a report from any goroutine. Reports are dropped while the
+// buffer is full since a newer one will follow.
+func (p *ProgressBar) Send(r Report) {
+	select {
+	case p.reports <- r:
+	default:
+	}
+}
+
+func (p *ProgressBar) Update(g *game.Game) error {
+	for {
+		select {
+		case r := <-p.reports:
+			p.active = true
+			p.fraction = r.Fraction
+			p.text = r.Text
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *ProgressBar) Draw(screen *ebiten.Image) {
+	if !p.active {
+		return
+	}
+
+	tx := p.x * float32(screen.Bounds().Dx())
+	ty := p.y * float32(screen.Bounds().Dy())
+	tw := p.width * float32(screen.Bounds().Dx())
+	th := p.height * float32(screen.Bounds().Dy())
+
+	vector.DrawFilledRect(
+		screen,
+		tx, ty,
+		tw, th,
+		p.trackColor,
+		false,
+	)
+
+	fraction := min(max(p.fraction, 0), 1)
+
+	vector.DrawFilledRect(
+		screen,
+		tx, ty,
+		tw*float32(fraction), th,
+		p.primaryColor,
+		false,
+	)
+
+	p.txtRenderer.SetColor(p.textColor)
+	p.txtRenderer.SetTarget(screen)
+	p.txtRenderer.SetSizePx(p.textSize)
+	p.txtRenderer.SetAlign(etxt.Bottom, etxt.Left)
+	p.txtRenderer.Draw(p.text, int(tx), int(ty-4))
+}
+
+// Finish hides the bar and discards reports that have not been drawn yet. It
+// must be called from the update goroutine once the work has returned.
+func (p *ProgressBar) Finish() {
+	for {
+		select {
+		case <-p.reports:
+		default:
+			p.active = false
+			p.fraction = 0
+			p.text = ""
+			return
+		}
+	}
+}
+
+func (p *ProgressBar) IsActive() bool {
+	return p.active
+}
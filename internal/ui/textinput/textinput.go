@@ -0,0 +1,125 @@
+package textinput
+
+import (
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	// CHAR_WIDTH estimates a character's width as a fraction of the text
+	// size, to show the end of text wider than the input.
+	CHAR_WIDTH = 0.6
+	// REPEAT_DELAY and REPEAT_INTERVAL are in ticks and make a held
+	// backspace keep deleting.
+	REPEAT_DELAY    = 30
+	REPEAT_INTERVAL = 3
+)
+
+// TextInput is a single line of editable text. Clicking it gives it the
+// keyboard until it is clicked outside of or Enter or Escape is pressed.
+type TextInput struct {
+	primaryColor color.Color
+	textColor    color.Color
+	textSize     int
+	text         []rune
+	x            float32
+	y            float32
+	width        float32
+	height       float32
+	txtRenderer  *etxt.Renderer
+	focused      bool
+	input        []rune
+}
+
+func NewTextInput(
+	x, y, width, height float32, textSize int,
+	primaryColor, textColor color.Color,
+	t *etxt.Renderer,
+) *TextInput {
+	return &TextInput{
+		x:            x,
+		y:            y,
+		width:        width,
+		height:       height,
+		textSize:     textSize,
+		primaryColor: primaryColor,
+		textColor:    textColor,
+		txtRenderer:  t,
+	}
+}
+
+func (i *TextInput) Update(g *game.Game) error {
+	lX, lY := g.LayoutF(1280, 720)
+	x, y := float32(lX), float32(lY)
+
+	mouseX, mouseY := ebiten.CursorPosition()
+	mX, mY := float32(mouseX), float32(mouseY)
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0) {
+		i.focused = mX > i.x*x && mX < (i.x+i.width)*x && mY > i.y*y && mY < (i.y+i.height)*y
+	}
+
+	if !i.focused {
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		i.focused = false
+		return nil
+	}
+
+	i.input = ebiten.AppendInputChars(i.input[:0])
+	i.text = append(i.text, i.input...)
+
+	if d := inpututil.KeyPressDuration(ebiten.KeyBackspace); d == 1 || (d >= REPEAT_DELAY && d%REPEAT_INTERVAL == 0) {
+		if len(i.text) > 0 {
+			i.text = i.text[:len(i.text)-1]
+		}
+	}
+
+	return nil
+}
+
+func (i *TextInput) Draw(screen *ebiten.Image) {
+	sw, sh := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+
+	tx := i.x * sw
+	ty := i.y * sh
+	tw := i.width * sw
+	th := i.height * sh
+
+	vector.DrawFilledRect(screen, tx, ty, tw, th, i.primaryColor, false)
+	if i.focused {
+		vector.StrokeRect(screen, tx, ty, tw, th, 2, i.textColor, false)
+	}
+
+	text := i.text
+	if i.focused {
+		text = append(text[:len(text):len(text)], '_')
+	}
+
+	// Keep the end of the text, where typing happens, in view.
+	maxChars := int((tw - 20) / (float32(i.textSize) * CHAR_WIDTH))
+	if maxChars > 0 && len(text) > maxChars {
+		text = text[len(text)-maxChars:]
+	}
+
+	i.txtRenderer.SetColor(i.textColor)
+	i.txtRenderer.SetTarget(screen)
+	i.txtRenderer.SetSizePx(i.textSize)
+	i.txtRenderer.SetAlign(etxt.YCenter, etxt.Left)
+	i.txtRenderer.Draw(string(text), int(tx+10), int(ty+th/2))
+}
+
+func (i *TextInput) SetText(t string) {
+	i.text = []rune(t)
+}
+
+func (i *TextInput) GetText() string {
+	return string(i.text)
+}
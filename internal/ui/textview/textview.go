@@ -0,0 +1,143 @@
+package textview
+
+import (
+	"image/color"
+
+	"github.com/DillonEnge/keizai-launcher/internal/game"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/tinne26/etxt"
+)
+
+const (
+	// SCROLL_LINES is how many lines one notch of the mouse wheel scrolls.
+	SCROLL_LINES = 3
+	// CHAR_WIDTH estimates a character's width as a fraction of the text
+	// size, to cut off lines wider than the view.
+	CHAR_WIDTH = 0.6
+)
+
+// TextView shows lines of text that scroll with the mouse wheel. In tail
+// mode it stays scrolled to the last line as lines are added; scrolling up
+// leaves tail mode.
+type TextView struct {
+	primaryColor color.Color
+	textColor    color.Color
+	textSize     int
+	x            float32
+	y            float32
+	width        float32
+	height       float32
+	lineHeight   float32
+	lines        []string
+	offset       int
+	visible      int
+	tail         bool
+	txtRenderer  *etxt.Renderer
+}
+
+func NewTextView(
+	x, y, width, height, lineHeight float32, textSize int,
+	primaryColor, textColor color.Color,
+	t *etxt.Renderer,
+) *TextView {
+	return &TextView{
+		x:            x,
+		y:            y,
+		width:        width,
+		height:       height,
+		lineHeight:   lineHeight,
+		textSize:     textSize,
+		primaryColor: primaryColor,
+		textColor:    textColor,
+		visible:      int(height / lineHeight),
+		tail:         true,
+		txtRenderer:  t,
+	}
+}
+
+func (v *TextView) Update(g *game.Game) error {
+	lX, lY := g.LayoutF(1280, 720)
+	x, y := float32(lX), float32(lY)
+
+	mouseX, mouseY := ebiten.CursorPosition()
+	mX, mY := float32(mouseX), float32(mouseY)
+
+	if mX < v.x*x || mX > (v.x+v.width)*x || mY < v.y*y || mY > (v.y+v.height)*y {
+		return nil
+	}
+
+	_, dy := ebiten.Wheel()
+	if dy == 0 {
+		return nil
+	}
+
+	v.scrollTo(v.offset - int(dy*SCROLL_LINES))
+	v.tail = v.offset == v.maxOffset()
+
+	return nil
+}
+
+func (v *TextView) Draw(screen *ebiten.Image) {
+	sw, sh := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+
+	tx := v.x * sw
+	ty := v.y * sh
+	tlh := v.lineHeight * sh
+
+	vector.DrawFilledRect(
+		screen,
+		tx, ty,
+		v.width*sw, v.height*sh,
+		v.primaryColor,
+		false,
+	)
+
+	v.txtRenderer.SetColor(v.textColor)
+	v.txtRenderer.SetTarget(screen)
+	v.txtRenderer.SetSizePx(v.textSize)
+	v.txtRenderer.SetAlign(etxt.YCenter, etxt.Left)
+
+	maxChars := int((v.width*sw - 20) / (float32(v.textSize) * CHAR_WIDTH))
+
+	for i := 0; i < v.visible && v.offset+i < len(v.lines); i++ {
+		line := []rune(v.lines[v.offset+i])
+		if maxChars > 0 && len(line) > maxChars {
+			line = line[:maxChars]
+		}
+
+		v.txtRenderer.Draw(string(line), int(tx+10), int(ty+float32(i)*tlh+tlh/2))
+	}
+}
+
+// SetLines replaces the text. In tail mode the view moves to the last line.
+func (v *TextView) SetLines(lines []string) {
+	v.lines = lines
+
+	if v.tail {
+		v.offset = v.maxOffset()
+	} else {
+		v.scrollTo(v.offset)
+	}
+}
+
+// SetTail turns tail mode on or off. Turning it on jumps to the last line.
+func (v *TextView) SetTail(tail bool) {
+	v.tail = tail
+
+	if tail {
+		v.offset = v.maxOffset()
+	}
+}
+
+func (v *TextView) Tail() bool {
+	return v.tail
+}
+
+func (v *TextView) scrollTo(offset int) {
+	v.offset = max(0, min(offset, v.maxOffset()))
+}
+
+func (v *TextView) maxOffset() int {
+	return max(0, len(v.lines)-v.visible)
+}
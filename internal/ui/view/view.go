@@ -7,6 +7,7 @@ import (
 
 type View struct {
 	children []game.Drawable
+	hidden   bool
 }
 
 func NewView(children ...game.Drawable) *View {
@@ -22,6 +23,10 @@ func (v *View) AddChild(d ...game.Drawable) {
 }
 
 func (v *View) Update(g *game.Game) error {
+	if v.hidden {
+		return nil
+	}
+
 	for _, c := range v.children {
 		if err := c.Update(g); err != nil {
 			return err
@@ -32,7 +37,19 @@ func (v *View) Update(g *game.Game) error {
 }
 
 func (v *View) Draw(screen *ebiten.Image) {
+	if v.hidden {
+		return
+	}
+
 	for _, c := range v.children {
 		c.Draw(screen)
 	}
 }
+
+func (v *View) SetHidden(h bool) {
+	v.hidden = h
+}
+
+func (v *View) IsHidden() bool {
+	return v.hidden
+}